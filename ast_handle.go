@@ -6,7 +6,7 @@ import (
 	"go/ast"
 	"go/parser"
 	"go/token"
-	"os"
+	"path"
 	"path/filepath"
 	"regexp"
 	"strconv"
@@ -22,16 +22,22 @@ const (
 )
 
 type structField struct {
-	fieldName string
-	fieldType string
-	comment   string
-	extends   map[string][]string
+	fieldName  string
+	fieldType  string
+	comment    string
+	extends    map[string][]string
+	pointer    bool           // 字段是指针类型
+	omitEmpty  bool           // json标签有omitempty
+	jsonString bool           // json标签有string，数字和布尔值序列化为字符串
+	inline     bool           // json标签有inline，结构体和匿名嵌入一样展开
+	pos        token.Position // 字段标签所在位置，没有标签时为字段位置
 }
 
 type structInfo struct {
-	name    string
-	comment string
-	list    []structField
+	name       string
+	comment    string
+	list       []structField
+	instanceOf string // 泛型实例的完整类型，用于判断名称相同的实例是否是同一个类型
 }
 
 type routeFuncInfo struct {
@@ -58,22 +64,41 @@ type astHandle struct {
 	uniqueFieldMap map[string]bool
 	modDir         string
 	sameStructs    map[string]string
+	genericParams  map[string][]string       // 泛型类型的类型参数名称
+	typeParams     map[string]bool           // 正在解析的泛型类型的类型参数
+	routesPos      map[string]token.Position // 路由所在位置
+	routeKeys      []string                  // 路由在文件中的顺序
+	diags          *diagnosticHandle         // 诊断收集器，为nil时不收集
+	fsys           fsHandle                  // 读取文件使用的文件系统
 }
 
 func (a *astHandle) load(filePath string, modName string, loadType astLoadType, modDir ...string) (err error) {
-	filePath, err = filepath.Abs(filePath)
+	if a.fsys == nil {
+		a.fsys = osFsHandle{}
+	}
+	filePath, err = a.fsys.abs(filePath)
 	if err != nil {
 		return
 	}
 	if len(modDir) > 0 {
-		a.modDir, _ = filepath.Abs(modDir[0])
+		a.modDir, _ = a.fsys.abs(modDir[0])
 	}
 	a.sameStructs = map[string]string{}
+	a.genericParams = map[string][]string{}
 	a.filePath = filePath
 	a.modName = modName
 	a.fSet = token.NewFileSet()
-	a.astFile, err = parser.ParseFile(a.fSet, filePath, nil, parser.ParseComments)
+	var src []byte
+	src, err = a.fsys.readFile(filePath)
+	if err == nil {
+		a.astFile, err = parser.ParseFile(a.fSet, filePath, src, parser.ParseComments)
+	}
 	if err != nil {
+		if a.diags != nil {
+			// 语法错误记录后继续解析其他文件
+			a.diags.addParseError(err)
+			return nil
+		}
 		return a.error(err.Error())
 	}
 	if loadType&astLoadTypeStruct == astLoadTypeStruct {
@@ -84,43 +109,44 @@ func (a *astHandle) load(filePath string, modName string, loadType astLoadType,
 	}
 	a.uniqueFieldMap = map[string]bool{}
 	if loadType&astLoadTypeDoc == astLoadTypeDoc {
-		if err = a.parseDoc(); err != nil {
-			return
-		}
+		a.parseDoc()
 	}
 	if loadType&astLoadTypeRoute == astLoadTypeRoute {
-		if err = a.parseRoutes(); err != nil {
-			return
-		}
+		a.parseRoutes()
 	}
 	return
 }
 
-func (a *astHandle) parseRoutes() (err error) {
+func (a *astHandle) parseRoutes() {
 	if a.astFile.Decls == nil {
 		return
 	}
 	a.routes = map[string]map[string]interface{}{}
+	a.routesPos = map[string]token.Position{}
 	var ok bool
 	var funcDecl *ast.FuncDecl
 	for _, decl := range a.astFile.Decls {
 		if funcDecl, ok = decl.(*ast.FuncDecl); ok {
-			var rsMap map[string]interface{}
-			rsMap, err = a.parseComments(funcDecl.Doc, validRoutesMap)
-			if err != nil {
-				return
-			}
+			rsMap := a.parseComments(funcDecl.Doc, validRoutesMap)
 			var routes []map[string]interface{}
 			if routes, ok = rsMap["@router"].([]map[string]interface{}); !ok {
 				continue
 			}
+			// go文档的 Deprecated: 注释和 @deprecated 一致
+			if reason, isDeprecated := deprecatedComment(funcDecl.Doc); isDeprecated && rsMap["@deprecated"] == nil {
+				rsMap["@deprecated"] = map[string]interface{}{"reason": reason}
+			}
 			for _, routeMap := range routes {
 				method := toString(routeMap["method"])
 				path := toString(routeMap["path"])
 				if method == "" || path == "" {
 					continue
 				}
+				if a.routes[path+"_"+method] == nil {
+					a.routeKeys = append(a.routeKeys, path+"_"+method)
+				}
 				a.routes[path+"_"+method] = rsMap
+				a.routesPos[path+"_"+method] = a.fSet.Position(funcDecl.Pos())
 				summary, _ := rsMap["@summary"].(string)
 				securityMap, _ := rsMap["@security"].(map[string]interface{})
 				security, _ := securityMap[sortField].([]string)
@@ -131,6 +157,15 @@ func (a *astHandle) parseRoutes() (err error) {
 	return
 }
 
+// 默认的operationId，方法为结构体名称+方法名称，例如 User.GetList 为 userGetList
+func (r routeFuncInfo) operationId() string {
+	name := r.funcStruct + r.funcName
+	if name == "" {
+		return ""
+	}
+	return strings.ToLower(name[:1]) + name[1:]
+}
+
 func (a *astHandle) parseRoutesFunc(path, method, summary string, security []string, funcDecl *ast.FuncDecl) {
 	if funcDecl.Name == nil {
 		return
@@ -150,14 +185,10 @@ func (a *astHandle) parseRoutesFunc(path, method, summary string, security []str
 	a.routesFunc = append(a.routesFunc, funcInfo)
 }
 
-func (a *astHandle) parseDoc() (err error) {
+func (a *astHandle) parseDoc() {
 	a.docs = map[string]interface{}{}
 	for _, comment := range a.astFile.Comments {
-		var rsMap map[string]interface{}
-		rsMap, err = a.parseComments(comment, validDocMap)
-		if err != nil {
-			return
-		}
+		rsMap := a.parseComments(comment, validDocMap)
 		for key, val := range rsMap {
 			a.docs[key] = val
 		}
@@ -165,42 +196,40 @@ func (a *astHandle) parseDoc() (err error) {
 	return
 }
 
-func (a *astHandle) parseComments(comment *ast.CommentGroup, validMap map[string]*validStruct) (rsMap map[string]interface{}, err error) {
+func (a *astHandle) parseComments(comment *ast.CommentGroup, validMap map[string]*validStruct) (rsMap map[string]interface{}) {
 	if comment == nil {
 		return
 	}
-	var key, value string
+	var key, validKey, value string
 	var isMull bool
 	var pos token.Pos
 	rsMap = map[string]interface{}{}
 	for _, v := range comment.List {
-		v.Text = remoteAnnotationSymbols(v.Text)
-		list := strings.Split(v.Text, firstKeyValueCutSign)
+		text := remoteAnnotationSymbols(v.Text)
+		list := strings.Split(text, firstKeyValueCutSign)
 		title := a.remoteAnnotationSymbols(list[0])
-		validData := validMap[title]
+		titleValidKey := validTitleKey(title)
+		validData := validMap[titleValidKey]
 		if validData == nil {
 			if isMull {
-				if v.Text == multiBorderSignEnd {
-					if err = a.parseCommentLine(v.Pos(), rsMap, key, a.remoteAnnotationSymbols(value), key, validMap); err != nil {
-						return
-					}
+				if text == multiBorderSignEnd {
+					a.parseCommentLine(v.Pos(), rsMap, key, a.remoteAnnotationSymbols(value), validKey, validMap)
 					isMull = false
 				}
-				if v.Text == "" {
+				if text == "" {
 					value += "\n"
 				} else {
-					value += v.Text + "\n"
+					value += text + "\n"
 				}
 				pos = v.Pos()
 			}
 			continue
 		}
 		if isMull {
-			if err = a.parseCommentLine(v.Pos(), rsMap, key, a.remoteAnnotationSymbols(value), key, validMap); err != nil {
-				return
-			}
+			a.parseCommentLine(v.Pos(), rsMap, key, a.remoteAnnotationSymbols(value), validKey, validMap)
 		}
 		key = title
+		validKey = titleValidKey
 		isMull = false
 		value = ""
 		other := a.remoteAnnotationSymbols(strings.Join(list[1:], firstKeyValueCutSign))
@@ -208,32 +237,36 @@ func (a *astHandle) parseComments(comment *ast.CommentGroup, validMap map[string
 			isMull = true
 			continue
 		}
-		if err = a.parseCommentLine(v.Pos(), rsMap, key, other, key, validMap); err != nil {
-			return
-		}
+		a.parseCommentLine(v.Pos(), rsMap, key, other, validKey, validMap)
 	}
 	if isMull {
-		if err = a.parseCommentLine(pos, rsMap, key, a.remoteAnnotationSymbols(value), key, validMap); err != nil {
-			return
-		}
+		a.parseCommentLine(pos, rsMap, key, a.remoteAnnotationSymbols(value), validKey, validMap)
 	}
 	return
 }
 
+// 注释标签对应的验证key，@x-开头的扩展字段使用 @x- 验证
+func validTitleKey(title string) string {
+	if strings.HasPrefix(title, extensionPrefix) && len(title) > len(extensionPrefix) {
+		return extensionPrefix
+	}
+	return title
+}
+
 func (a *astHandle) parseCommentLine(
 	pos token.Pos,
 	rsMap map[string]interface{},
 	key, value, validKey string,
 	validMap map[string]*validStruct,
-) (err error) {
+) {
 	validData := validMap[validKey]
-	if validMap == nil {
+	if validData == nil {
 		return
 	}
 	if validData.isUnique {
 		uniqueKey := fmt.Sprintf("%v_%v", validKey, value)
 		if a.uniqueFieldMap[uniqueKey] {
-			err = a.errorPos(fmt.Sprintf(errorRepeat, key, value), pos)
+			a.errorPos(CodeRepeat, validKey, fmt.Sprintf(errorRepeat, key, value), pos)
 			return
 		}
 		a.uniqueFieldMap[uniqueKey] = true
@@ -241,33 +274,46 @@ func (a *astHandle) parseCommentLine(
 	switch validData.valType {
 	case validTypeString:
 		if len(validData.valEnum) > 0 && inArray(value, validData.valEnum) == -1 {
-			err = a.errorPos(fmt.Sprintf(errorNotIn, value, strings.Join(validData.valEnum, ",")), pos)
+			a.errorPos(CodeNotIn, validKey, fmt.Sprintf(errorNotIn, value, strings.Join(validData.valEnum, ",")), pos)
 			return
 		}
 		rsMap[key] = value
 	case validTypeInteger:
 		if len(validData.valEnum) > 0 && inArray(value, validData.valEnum) == -1 {
-			err = a.errorPos(fmt.Sprintf(errorNotIn, value, strings.Join(validData.valEnum, ",")), pos)
+			a.errorPos(CodeNotIn, validKey, fmt.Sprintf(errorNotIn, value, strings.Join(validData.valEnum, ",")), pos)
+			return
+		}
+		if _, err := strconv.Atoi(value); err != nil {
+			a.errorPos(CodeNotInteger, validKey, fmt.Sprintf(errorType, value, "integer"), pos)
 			return
 		}
-		if _, err = strconv.Atoi(value); err != nil {
-			err = a.errorPos(err.Error(), pos)
+		rsMap[key] = value
+	case validTypeStatus:
+		if !isStatus(value) {
+			a.errorPos(CodeNotStatus, validKey, fmt.Sprintf(errorStatus, value), pos)
 			return
 		}
 		rsMap[key] = value
 	case validTypeBool:
 		if len(validData.valEnum) > 0 && inArray(value, validData.valEnum) == -1 {
-			err = a.errorPos(fmt.Sprintf(errorNotIn, value, strings.Join(validData.valEnum, ",")), pos)
+			a.errorPos(CodeNotIn, validKey, fmt.Sprintf(errorNotIn, value, strings.Join(validData.valEnum, ",")), pos)
 			return
 		}
 		if value != "true" && value != "false" {
-			err = a.errorPos(fmt.Sprintf(errorType, value, "bool"), pos)
+			a.errorPos(CodeNotBool, validKey, fmt.Sprintf(errorType, value, "bool"), pos)
 			return
 		}
 		rsMap[key] = value
+	case validTypeValue:
+		var rs interface{}
+		if err := json.Unmarshal([]byte(value), &rs); err != nil {
+			rs = value
+		}
+		rsMap[key] = rs
 	case validTypeJson:
 		var rs interface{}
-		if err = json.Unmarshal([]byte(value), &rs); err != nil {
+		if err := json.Unmarshal([]byte(value), &rs); err != nil {
+			a.errorPos(CodeInvalidJson, validKey, err.Error(), pos)
 			return
 		}
 		rsMap[key] = rs
@@ -275,14 +321,25 @@ func (a *astHandle) parseCommentLine(
 		if validData.cutListSign == "" {
 			return
 		}
-		rs := strings.Split(value, validData.cutListSign)
-		for k, v := range rs {
+		var rs []string
+		for _, v := range strings.Split(value, validData.cutListSign) {
 			v = strings.Trim(v, " ")
 			if len(validData.valEnum) > 0 && inArray(v, validData.valEnum) == -1 {
-				err = a.errorPos(fmt.Sprintf(errorNotIn, v, strings.Join(validData.valEnum, ",")), pos)
-				return
+				a.errorPos(CodeNotIn, validKey, fmt.Sprintf(errorNotIn, v, strings.Join(validData.valEnum, ",")), pos)
+				continue
+			}
+			rs = append(rs, v)
+		}
+		rsMap[key] = rs
+	case validTypeMediaType:
+		var rs []string
+		for _, v := range strings.Split(value, validData.cutListSign) {
+			v = strings.Trim(v, " ")
+			if !isMediaType(v) {
+				a.errorPos(CodeNotMediaType, validKey, fmt.Sprintf(errorMediaType, v), pos)
+				continue
 			}
-			rs[k] = v
+			rs = append(rs, v)
 		}
 		rsMap[key] = rs
 	case validTypeMapArray, validTypeMap:
@@ -297,8 +354,8 @@ func (a *astHandle) parseCommentLine(
 			newV := a.remoteAnnotationSymbols(v)
 			if validData.cutKeyValSign == "" {
 				if len(validData.valEnum) > 0 && inArray(newV, validData.valEnum) == -1 {
-					err = a.errorPos(fmt.Sprintf(errorNotIn, newV, strings.Join(validData.valEnum, ",")), pos)
-					return
+					a.errorPos(CodeNotIn, validKey, fmt.Sprintf(errorNotIn, newV, strings.Join(validData.valEnum, ",")), pos)
+					continue
 				}
 				tmpMap[newV] = "true"
 				tmpSorts = append(tmpSorts, newV)
@@ -311,10 +368,8 @@ func (a *astHandle) parseCommentLine(
 				childValidData := validMap[childValidTitle]
 				if childValidData != nil {
 					// 所有key通过
-					if err = a.parseCommentLine(pos, tmpMap, title, a.remoteAnnotationSymbols(strings.Join(vList[1:],
-						validData.cutKeyValSign)), childValidTitle, validMap); err != nil {
-						return
-					}
+					a.parseCommentLine(pos, tmpMap, title, a.remoteAnnotationSymbols(strings.Join(vList[1:],
+						validData.cutKeyValSign)), childValidTitle, validMap)
 					tmpSorts = append(tmpSorts, title)
 					continue
 				}
@@ -328,15 +383,13 @@ func (a *astHandle) parseCommentLine(
 					continue
 				}
 				beforeKey = title
-				if err = a.parseCommentLine(pos, tmpMap, title, a.remoteAnnotationSymbols(strings.Join(vList[1:],
-					validData.cutKeyValSign)), childValidTitle, validMap); err != nil {
-					return
-				}
+				a.parseCommentLine(pos, tmpMap, title, a.remoteAnnotationSymbols(strings.Join(vList[1:],
+					validData.cutKeyValSign)), childValidTitle, validMap)
 				tmpSorts = append(tmpSorts, title)
 			} else {
 				if len(validData.valEnum) > 0 && inArray(newV, validData.valEnum) == -1 {
-					err = a.errorPos(fmt.Sprintf(errorNotIn, newV, strings.Join(validData.valEnum, ",")), pos)
-					return
+					a.errorPos(CodeNotIn, validKey, fmt.Sprintf(errorNotIn, newV, strings.Join(validData.valEnum, ",")), pos)
+					continue
 				}
 				tmpMap[newV] = "true"
 				tmpSorts = append(tmpSorts, newV)
@@ -350,6 +403,9 @@ func (a *astHandle) parseCommentLine(
 		} else {
 			rsList, _ := rsMap[key].([]map[string]interface{})
 			if len(tmpMap) > 0 {
+				if validData.isPos {
+					tmpMap[posField] = a.fSet.Position(pos)
+				}
 				rsList = append(rsList, tmpMap)
 			}
 			rsMap[key] = rsList
@@ -418,14 +474,11 @@ func (a *astHandle) structImport() {
 	if a.modName == "" {
 		return
 	}
-	pwd := a.modDir
-	if pwd == "" {
-		pwd, _ = os.Getwd()
+	rel, err := a.fsys.rel(a.modDir, filepath.Dir(a.filePath))
+	if err != nil {
+		rel = "."
 	}
-	a.structPrefix = strings.TrimPrefix(a.filePath, pwd)
-	a.structPrefix = filepath.Dir(a.structPrefix)
-	a.structPrefix = filepath.Join(a.modName, a.structPrefix)
-	a.structPrefix = strings.ReplaceAll(a.structPrefix, "\\", "/") + "."
+	a.structPrefix = path.Join(a.modName, filepath.ToSlash(rel)) + "."
 }
 
 func (a *astHandle) parseStruct(typeSpec *ast.TypeSpec) (strInfo *structInfo, bl bool) {
@@ -438,6 +491,21 @@ func (a *astHandle) parseStruct(typeSpec *ast.TypeSpec) (strInfo *structInfo, bl
 	if typeSpec.Type == nil {
 		return
 	}
+	// 泛型类型的字段中类型参数使用 {T} 占位，实例化时替换
+	if typeSpec.TypeParams != nil {
+		a.typeParams = map[string]bool{}
+		var params []string
+		for _, field := range typeSpec.TypeParams.List {
+			for _, name := range field.Names {
+				a.typeParams[name.Name] = true
+				params = append(params, name.Name)
+			}
+		}
+		a.genericParams[a.structPrefix+strInfo.name] = params
+		defer func() {
+			a.typeParams = nil
+		}()
+	}
 	var structType *ast.StructType
 	if structType, ok = typeSpec.Type.(*ast.StructType); !ok {
 		sameTypes := a.getCallType(typeSpec.Type)
@@ -448,7 +516,7 @@ func (a *astHandle) parseStruct(typeSpec *ast.TypeSpec) (strInfo *structInfo, bl
 		return
 	}
 	for _, field := range structType.Fields.List {
-		fieldInfo := structField{}
+		fieldInfo := structField{pos: a.fSet.Position(field.Pos())}
 		// 获取名称
 		fieldName := ""
 		if len(field.Names) > 0 {
@@ -457,17 +525,21 @@ func (a *astHandle) parseStruct(typeSpec *ast.TypeSpec) (strInfo *structInfo, bl
 		fieldInfo.fieldName = fieldName
 		// 获取类型
 		fieldInfo.fieldType = a.getCallType(field.Type)
+		_, fieldInfo.pointer = field.Type.(*ast.StarExpr)
+		ignore := false
 		// 获取标签
 		if field.Tag != nil {
+			fieldInfo.pos = a.fSet.Position(field.Tag.Pos())
 			rsMap := a.getCallTags(field.Tag)
 			if rsMap["xml"] != nil {
 				rsList, _ := rsMap["xml"].([]string)
 				fieldInfo.fieldName = rsList[0]
+				ignore = rsList[0] == "-"
 				delete(rsMap, "xml")
 			}
 			if rsMap["json"] != nil {
 				rsList, _ := rsMap["json"].([]string)
-				fieldInfo.fieldName = rsList[0]
+				ignore = a.parseJsonTag(&fieldInfo, rsList)
 				delete(rsMap, "json")
 			}
 			// 覆盖类型
@@ -482,7 +554,13 @@ func (a *astHandle) parseStruct(typeSpec *ast.TypeSpec) (strInfo *structInfo, bl
 			if rsMap["openapi"] != nil {
 				switch rsVal := rsMap["openapi"].(type) {
 				case []string:
-					fieldInfo.extends[rsVal[0]] = []string{"true"}
+					// 只有一个属性时没有按 = 切割，例如 openapi:"minimum=10"
+					key, value := getIndexFirst(strings.Join(rsVal, thirdListCutSign), secondKeyValueCutSign)
+					if value == "" {
+						fieldInfo.extends[key] = []string{"true"}
+					} else {
+						fieldInfo.extends[key] = strings.Split(value, thirdListCutSign)
+					}
 				case map[string][]string:
 					for k1, v1 := range rsVal {
 						fieldInfo.extends[k1] = v1
@@ -498,9 +576,16 @@ func (a *astHandle) parseStruct(typeSpec *ast.TypeSpec) (strInfo *structInfo, bl
 				fieldInfo.extends[k1] = v1List
 			}
 		}
-		if fieldInfo.fieldName == "-" {
+		if ignore {
 			continue
 		}
+		// go文档的 Deprecated: 注释，标签中指定时以标签为准
+		if _, isDeprecated := deprecatedComment(field.Doc, field.Comment); isDeprecated && fieldInfo.extends["deprecated"] == nil {
+			if fieldInfo.extends == nil {
+				fieldInfo.extends = map[string][]string{}
+			}
+			fieldInfo.extends["deprecated"] = []string{"true"}
+		}
 		// 获取注释
 		if field.Comment != nil {
 			fieldInfo.comment = a.remoteAnnotationSymbols(field.Comment.List[0].Text)
@@ -511,6 +596,56 @@ func (a *astHandle) parseStruct(typeSpec *ast.TypeSpec) (strInfo *structInfo, bl
 	return
 }
 
+// 解析json标签，名称为空时使用字段名称，"-" 忽略字段，"-," 的名称为 -
+// inline 的结构体和匿名嵌入的结构体一样展开，其他类型保留字段名称
+func (a *astHandle) parseJsonTag(fieldInfo *structField, rsList []string) (ignore bool) {
+	if len(rsList) == 1 && rsList[0] == "-" {
+		return true
+	}
+	if rsList[0] != "" {
+		fieldInfo.fieldName = rsList[0]
+	}
+	for _, option := range rsList[1:] {
+		switch option {
+		case "omitempty":
+			fieldInfo.omitEmpty = true
+		case "string":
+			fieldInfo.jsonString = true
+		case "inline":
+			fieldInfo.inline = true
+		}
+	}
+	return
+}
+
+// 注释中以 Deprecated: 开头的行，返回废弃原因，原因到空行或者下一个@注释结束
+func deprecatedComment(groups ...*ast.CommentGroup) (reason string, ok bool) {
+	for _, group := range groups {
+		if group == nil {
+			continue
+		}
+		var lines []string
+		for _, line := range strings.Split(group.Text(), "\n") {
+			line = strings.TrimSpace(line)
+			if !ok {
+				if strings.HasPrefix(line, "Deprecated:") {
+					ok = true
+					lines = append(lines, strings.TrimSpace(strings.TrimPrefix(line, "Deprecated:")))
+				}
+				continue
+			}
+			if line == "" || strings.HasPrefix(line, "@") {
+				break
+			}
+			lines = append(lines, line)
+		}
+		if ok {
+			return strings.TrimSpace(strings.Join(lines, " ")), true
+		}
+	}
+	return
+}
+
 func (a *astHandle) getCallTags(expr ast.Expr) (rsMap map[string]interface{}) {
 	rsMap = make(map[string]interface{})
 	switch val := expr.(type) {
@@ -552,12 +687,18 @@ func (a *astHandle) getCallType(expr ast.Expr) string {
 	var ok bool
 	switch val := expr.(type) {
 	case *ast.Ident:
+		if a.typeParams[val.Name] {
+			// 泛型的类型参数
+			return "{" + val.Name + "}"
+		}
 		// 常规类型
 		switch val.Name {
 		case "int", "int8", "int16", "int32", "int64",
 			"uint", "uint8", "uint16", "uint32", "uint64",
-			"float32", "float64", "string", "bool":
+			"float32", "float64", "string", "bool", "byte", "rune":
 			return val.Name
+		case "any":
+			return anyType
 		}
 		return a.structPrefix + val.Name
 	case *ast.ArrayType:
@@ -576,20 +717,32 @@ func (a *astHandle) getCallType(expr ast.Expr) string {
 		return "map[" + a.getCallType(val.Key) + "]" + a.getCallType(val.Value)
 	case *ast.InterfaceType:
 		// interface类型
-		return "interface{}"
+		return anyType
 	case *ast.SelectorExpr:
 		// 引用类型
 		var xTypeExpr *ast.Ident
 		if xTypeExpr, ok = val.X.(*ast.Ident); !ok {
 			return ""
 		}
-		if a.importMap[xTypeExpr.Name] != "" {
-			xTypeExpr.Name = a.importMap[xTypeExpr.Name]
+		// 不能修改ast节点，否则多次解析同一节点会重复替换
+		importName := xTypeExpr.Name
+		if a.importMap[importName] != "" {
+			importName = a.importMap[importName]
 		}
-		return xTypeExpr.Name + "." + val.Sel.Name
+		return importName + "." + val.Sel.Name
 	case *ast.StarExpr:
 		// 该项目指针类型使用原类型
 		return a.getCallType(val.X)
+	case *ast.IndexExpr:
+		// 泛型实例，例如 Result[User]
+		return a.getCallType(val.X) + "[" + a.getCallType(val.Index) + "]"
+	case *ast.IndexListExpr:
+		// 多个类型参数的泛型实例，例如 Pair[User, Role]
+		var list []string
+		for _, index := range val.Indices {
+			list = append(list, a.getCallType(index))
+		}
+		return a.getCallType(val.X) + "[" + strings.Join(list, thirdListCutSign) + "]"
 	}
 	return ""
 }
@@ -603,10 +756,11 @@ func (a *astHandle) remoteAnnotationSymbols(s string) string {
 	return s
 }
 
-func (a *astHandle) errorPos(err string, pos token.Pos) error {
-	return fmt.Errorf("%v: %v", a.fSet.Position(pos), err)
+// 记录注释错误，继续解析后续注释
+func (a *astHandle) errorPos(code, key, err string, pos token.Pos) {
+	a.diags.add(SeverityError, code, key, err, a.fSet.Position(pos))
 }
 
 func (a *astHandle) error(err string) error {
-	return fmt.Errorf("%v", err)
+	return &Error{Kind: ErrorKindParse, Msg: err}
 }
@@ -4,9 +4,12 @@ import (
 	"fmt"
 	"github.com/goodluckxu-go/openapi"
 	"github.com/urfave/cli/v2"
+	"io/fs"
 	"log"
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
 )
 
 const (
@@ -14,8 +17,16 @@ const (
 	defaultRouteDir = defaultRootDir
 	defaultDocPath  = defaultRootDir + "doc.go"
 	defaultOutDir   = defaultRootDir + "docs"
+	defaultOutName  = "openapi"
+	swaggerOutName  = "swagger"
+	defaultFilePerm = "0644"
+	// 路由没有成功的返回时添加的返回状态码，none 表示不添加
+	defaultFallbackStatus = "200"
+	noneFallbackStatus    = "none"
 )
 
+var defaultFormats = []string{openapi.FormatYAML, openapi.FormatJSON}
+
 func main() {
 	app := cli.NewApp()
 	app.Version = openapi.Version
@@ -43,8 +54,28 @@ func main() {
 					outDir = defaultOutDir
 				}
 				ginGenerateRouteDir, _ := ctx.Value("generateGinRouteDir").(string)
-				openapi.GenerateOpenAPI(rootDir, routeDir, docPath, outDir, ginGenerateRouteDir)
-				return nil
+				emitters, err := newEmitters(ctx, outDir)
+				if err != nil {
+					return err
+				}
+				_, err = openapi.Run(ctx.Context, openapi.Config{
+					Options: openapi.Options{
+						RootDir:            rootDir,
+						RouteDir:           routeDir,
+						DocPath:            docPath,
+						OpenAPIVersion:     ctx.String("openapi-version"),
+						FallbackResponse:   newFallbackResponse(ctx),
+						TypeMappingPath:    ctx.String("type-mapping"),
+						RequiredPolicy:     ctx.String("required-policy"),
+						PointerNotNullable: ctx.Bool("pointer-not-nullable"),
+						OnWarning: func(d openapi.Diagnostic) {
+							log.Println(d)
+						},
+					},
+					Emitters:    emitters,
+					GinRouteDir: ginGenerateRouteDir,
+				})
+				return err
 			},
 			Flags: []cli.Flag{
 				&cli.StringFlag{
@@ -71,6 +102,52 @@ func main() {
 					Name:  "generateGinRouteDir",
 					Usage: "gin生成路由文件",
 				},
+				&cli.StringSliceFlag{
+					Name:        "format",
+					Usage:       "输出格式，可以多个，值包括 " + strings.Join(openapi.Formats(), ","),
+					DefaultText: strings.Join(defaultFormats, ","),
+				},
+				&cli.StringFlag{
+					Name:        "outName",
+					Usage:       "输出文件名称，不包含后缀",
+					DefaultText: defaultOutName,
+				},
+				&cli.StringFlag{
+					Name:        "filePerm",
+					Usage:       "输出文件权限，八进制",
+					DefaultText: defaultFilePerm,
+				},
+				&cli.StringFlag{
+					Name:        "openapi-version",
+					Usage:       "输出的openapi版本，值包括 " + openapi.OpenAPIVersion30 + "," + openapi.OpenAPIVersion31,
+					DefaultText: openapi.OpenAPIVersion30,
+				},
+				&cli.StringFlag{
+					Name:        "fallback-status",
+					Usage:       "路由没有2XX、3XX和default返回时添加的返回状态码，为 " + noneFallbackStatus + " 时不添加",
+					DefaultText: defaultFallbackStatus,
+				},
+				&cli.StringFlag{
+					Name:  "type-mapping",
+					Usage: "类型映射配置文件，json或者yaml格式，key为完整的类型名称，值包括 type,format,nullable,description",
+				},
+				&cli.StringFlag{
+					Name:        "required-policy",
+					Usage:       "结构体字段是否必填的判断方式，值包括 " + openapi.RequiredPolicyTag + "(只使用required标签)," + openapi.RequiredPolicyOmitempty + "(json标签没有omitempty的非指针字段必填)",
+					DefaultText: openapi.RequiredPolicyTag,
+				},
+				&cli.BoolFlag{
+					Name:  "pointer-not-nullable",
+					Usage: "指针字段不输出nullable，默认指针字段可以为null",
+				},
+				&cli.BoolFlag{
+					Name:  "swagger",
+					Usage: "同时输出swagger2.0文档，文件名称为 " + swaggerOutName,
+				},
+				&cli.BoolFlag{
+					Name:  "stdout",
+					Usage: "输出到标准输出，不生成文件",
+				},
 			},
 		},
 		{
@@ -116,3 +193,71 @@ func main() {
 		log.Fatal(err)
 	}
 }
+
+func newFallbackResponse(ctx *cli.Context) *openapi.FallbackResponse {
+	status := ctx.String("fallback-status")
+	switch status {
+	case "":
+		status = defaultFallbackStatus
+	case noneFallbackStatus:
+		status = ""
+	}
+	return &openapi.FallbackResponse{Status: status}
+}
+
+func newEmitters(ctx *cli.Context, outDir string) (emitters []openapi.Emitter, err error) {
+	formats := ctx.StringSlice("format")
+	if len(formats) == 0 {
+		formats = defaultFormats
+	}
+	swagger := ctx.Bool("swagger")
+	onWarning := func(d openapi.Diagnostic) {
+		log.Println(d)
+	}
+	if ctx.Bool("stdout") {
+		for _, format := range formats {
+			var emitter *openapi.FileEmitter
+			if emitter, err = openapi.NewStdoutEmitter(format); err != nil {
+				return
+			}
+			emitters = append(emitters, emitter)
+			if swagger {
+				emitters = append(emitters, &openapi.SwaggerEmitter{Format: format, OnWarning: onWarning})
+				// 每种格式的转换警告相同，只输出一次
+				onWarning = nil
+			}
+		}
+		return
+	}
+	outName, _ := ctx.Value("outName").(string)
+	if outName == "" {
+		outName = defaultOutName
+	}
+	filePerm, _ := ctx.Value("filePerm").(string)
+	if filePerm == "" {
+		filePerm = defaultFilePerm
+	}
+	perm, err := strconv.ParseUint(filePerm, 8, 32)
+	if err != nil {
+		return nil, fmt.Errorf("文件权限 %v 不是八进制数字", filePerm)
+	}
+	for _, format := range formats {
+		var emitter *openapi.FileEmitter
+		emitter, err = openapi.NewFileEmitter(format, outDir, outName, fs.FileMode(perm))
+		if err != nil {
+			return
+		}
+		emitters = append(emitters, emitter)
+		if swagger {
+			var swaggerEmitter *openapi.SwaggerEmitter
+			swaggerEmitter, err = openapi.NewSwaggerEmitter(format, outDir, swaggerOutName, fs.FileMode(perm))
+			if err != nil {
+				return
+			}
+			swaggerEmitter.OnWarning = onWarning
+			emitters = append(emitters, swaggerEmitter)
+			onWarning = nil
+		}
+	}
+	return
+}
@@ -1,13 +1,17 @@
 package openapi
 
 const (
-	firstKeyValueCutSign  = ":"    // 第一次切割键值标志
-	secondListCutSign     = ";"    // 第二次切割数组标志
-	secondKeyValueCutSign = "="    // 第二次切割键值标志
-	thirdListCutSign      = ","    // 第三次切割数组标志
-	multiBorderSign       = "|-"   // 多行标志
-	multiBorderSignEnd    = "-|"   // 多行标志结束，不存在则在下一个可用标签前结束
-	sortField             = "SORT" // 对象排序字段
+	firstKeyValueCutSign  = ":"           // 第一次切割键值标志
+	secondListCutSign     = ";"           // 第二次切割数组标志
+	secondKeyValueCutSign = "="           // 第二次切割键值标志
+	thirdListCutSign      = ","           // 第三次切割数组标志
+	multiBorderSign       = "|-"          // 多行标志
+	multiBorderSignEnd    = "-|"          // 多行标志结束，不存在则在下一个可用标签前结束
+	sortField             = "SORT"        // 对象排序字段
+	posField              = "POS"         // 注释位置字段
+	extensionPrefix       = "@x-"         // 扩展字段标志
+	anyType               = "interface{}" // 任意类型
+	mapKeyTypeExtension   = "x-key-type"  // map的key类型
 )
 
 const (
@@ -18,9 +22,7 @@ const (
 	validTypeBool
 	validTypeInteger
 	validTypeJson
-)
-
-var (
-	projectModName = ""
-	modPathMap     modHandle
+	validTypeStatus
+	validTypeMediaType
+	validTypeValue // json值，不是json时为字符串
 )
@@ -0,0 +1,167 @@
+package openapi
+
+import (
+	"fmt"
+	"go/scanner"
+	"go/token"
+	"sort"
+	"strings"
+)
+
+// Severity 诊断级别
+type Severity int
+
+const (
+	SeverityError   Severity = iota + 1 // 错误，生成失败
+	SeverityWarning                     // 警告，不影响生成
+)
+
+func (s Severity) String() string {
+	switch s {
+	case SeverityError:
+		return "error"
+	case SeverityWarning:
+		return "warning"
+	}
+	return "unknown"
+}
+
+// 诊断码，值保持不变，便于工具按码过滤
+const (
+	CodeParse            = "OA1000" // go 文件语法错误
+	CodeNotIn            = "OA1001" // 值不在枚举中
+	CodeNotInteger       = "OA1002" // 值不是整数
+	CodeNotBool          = "OA1003" // 值不是布尔值
+	CodeInvalidJson      = "OA1004" // 值不是合法的json
+	CodeRepeat           = "OA1005" // 唯一值重复
+	CodeNotStatus        = "OA1006" // 值不是状态码
+	CodeNotMediaType     = "OA1007" // 值不是媒体类型
+	CodeFormatValue      = "OA1008" // 值不符合类型映射的格式，已忽略
+	CodeInline           = "OA1009" // json标签的inline只能用于结构体，已作为普通字段
+	CodeRouteRepeat      = "OA2001" // 路由重复
+	CodeSecurityNotFound = "OA2002" // 验证字段未在 @components.securitySchemes 中定义
+	CodeOperationId      = "OA2003" // operationId重复
+	CodeRefNotFound      = "OA2004" // 引用的组件未在 @components 中定义，或者参数的结构体不存在
+	CodeVersion          = "OA3001" // 注释在当前openapi版本中不支持
+	CodeSwaggerMediaType = "OA4001" // swagger2.0只支持一个请求或返回类型
+	CodeSwaggerSchema    = "OA4002" // swagger2.0不支持oneOf、anyOf和not
+	CodeSwaggerCookie    = "OA4003" // swagger2.0不支持cookie参数
+	CodeSwaggerServer    = "OA4004" // swagger2.0只支持一个服务地址
+	CodeSwaggerStatus    = "OA4005" // swagger2.0不支持状态码范围
+	CodeSwaggerCallback  = "OA4006" // swagger2.0不支持回调
+)
+
+// Diagnostic 一条注释诊断信息
+type Diagnostic struct {
+	Pos      token.Position // 位置 file:line:column
+	Key      string         // 注释标签，例如 @param._.in
+	Severity Severity       // 级别
+	Code     string         // 诊断码
+	Msg      string         // 信息
+}
+
+func (d Diagnostic) String() string {
+	s := ""
+	if d.Pos.IsValid() {
+		s = d.Pos.String() + ": "
+	} else if d.Pos.Filename != "" {
+		s = d.Pos.Filename + ": "
+	}
+	s += d.Severity.String() + " " + d.Code
+	if d.Key != "" {
+		s += " " + d.Key
+	}
+	return s + ": " + d.Msg
+}
+
+// Diagnostics 一次生成中收集到的所有诊断信息
+type Diagnostics []Diagnostic
+
+// HasError 是否存在错误级别的诊断
+func (d Diagnostics) HasError() bool {
+	for _, v := range d {
+		if v.Severity == SeverityError {
+			return true
+		}
+	}
+	return false
+}
+
+func (d Diagnostics) String() string {
+	list := make([]string, 0, len(d))
+	for _, v := range d {
+		list = append(list, v.String())
+	}
+	return strings.Join(list, "\n")
+}
+
+// 诊断收集器，为nil时不收集
+type diagnosticHandle struct {
+	list Diagnostics
+}
+
+func (d *diagnosticHandle) add(severity Severity, code, key, msg string, pos token.Position) {
+	if d == nil {
+		return
+	}
+	d.list = append(d.list, Diagnostic{
+		Pos:      pos,
+		Key:      key,
+		Severity: severity,
+		Code:     code,
+		Msg:      msg,
+	})
+}
+
+func (d *diagnosticHandle) addParseError(err error) {
+	if d == nil {
+		return
+	}
+	if list, ok := err.(scanner.ErrorList); ok {
+		for _, v := range list {
+			d.add(SeverityError, CodeParse, "", v.Msg, v.Pos)
+		}
+		return
+	}
+	d.add(SeverityError, CodeParse, "", err.Error(), token.Position{})
+}
+
+func (d *diagnosticHandle) hasError() bool {
+	return d != nil && d.list.HasError()
+}
+
+// 按文件、行、列排序，保证输出稳定
+func (d *diagnosticHandle) sorted() Diagnostics {
+	if d == nil {
+		return nil
+	}
+	list := append(Diagnostics{}, d.list...)
+	sort.SliceStable(list, func(i, j int) bool {
+		if list[i].Pos.Filename != list[j].Pos.Filename {
+			return list[i].Pos.Filename < list[j].Pos.Filename
+		}
+		if list[i].Pos.Line != list[j].Pos.Line {
+			return list[i].Pos.Line < list[j].Pos.Line
+		}
+		return list[i].Pos.Column < list[j].Pos.Column
+	})
+	return list
+}
+
+func (d *diagnosticHandle) error() error {
+	if !d.hasError() {
+		return nil
+	}
+	list := d.sorted()
+	count := 0
+	for _, v := range list {
+		if v.Severity == SeverityError {
+			count++
+		}
+	}
+	return &Error{
+		Kind:        ErrorKindAnnotation,
+		Msg:         fmt.Sprintf("注释存在 %v 个错误", count),
+		Diagnostics: list,
+	}
+}
@@ -0,0 +1,189 @@
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/getkin/kin-openapi/openapi3"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// 内置的输出格式
+const (
+	FormatYAML        = "yaml"         // yaml格式
+	FormatJSON        = "json"         // 缩进的json格式
+	FormatJSONCompact = "json-compact" // 紧凑的json格式
+)
+
+// 默认的文件权限
+const defaultFilePerm fs.FileMode = 0644
+
+// Emitter 输出生成的文档，可以自定义实现
+type Emitter interface {
+	Emit(t *openapi3.T) error
+}
+
+// EmitterFunc 函数形式的 Emitter
+type EmitterFunc func(t *openapi3.T) error
+
+func (f EmitterFunc) Emit(t *openapi3.T) error {
+	return f(t)
+}
+
+// Marshaler 将文档转换为指定格式的内容
+type Marshaler func(t *openapi3.T) ([]byte, error)
+
+type formatInfo struct {
+	ext     string
+	marshal Marshaler
+}
+
+var (
+	formatMutex sync.RWMutex
+	formatMap   = map[string]formatInfo{
+		FormatYAML: {ext: ".yaml", marshal: func(t *openapi3.T) ([]byte, error) {
+			return yamlMarshal(t)
+		}},
+		FormatJSON: {ext: ".json", marshal: func(t *openapi3.T) ([]byte, error) {
+			return json.MarshalIndent(t, "", "    ")
+		}},
+		FormatJSONCompact: {ext: ".min.json", marshal: func(t *openapi3.T) ([]byte, error) {
+			return json.Marshal(t)
+		}},
+	}
+)
+
+// RegisterFormat 注册输出格式，注册后 FileEmitter 和命令行的 --format 可以使用该名称，ext为默认文件后缀
+func RegisterFormat(name, ext string, marshal Marshaler) {
+	formatMutex.Lock()
+	defer formatMutex.Unlock()
+	formatMap[name] = formatInfo{ext: ext, marshal: marshal}
+}
+
+// Formats 所有已注册的输出格式名称
+func Formats() []string {
+	formatMutex.RLock()
+	defer formatMutex.RUnlock()
+	return formatNames()
+}
+
+// 调用时需要持有formatMutex
+func formatNames() []string {
+	var list []string
+	for k := range formatMap {
+		list = append(list, k)
+	}
+	sort.Strings(list)
+	return list
+}
+
+func getFormat(name string) (info formatInfo, err error) {
+	formatMutex.RLock()
+	defer formatMutex.RUnlock()
+	var ok bool
+	if info, ok = formatMap[name]; !ok {
+		err = newErrorMsg(ErrorKindWrite, "输出格式"+errorNotIn, name, strings.Join(formatNames(), ","))
+	}
+	return
+}
+
+// FileEmitter 按格式输出到文件，Path为空时输出到Writer
+type FileEmitter struct {
+	Format string      // 输出格式，例如 yaml,json,json-compact 或者注册的格式
+	Path   string      // 文件路径，为空时输出到Writer
+	Perm   fs.FileMode // 文件权限，为0时使用0644
+	Writer io.Writer   // Path为空时的输出，为nil时输出到标准输出
+}
+
+// NewFileEmitter 输出到 dir 目录中的 name+格式后缀 文件
+func NewFileEmitter(format, dir, name string, perm fs.FileMode) (*FileEmitter, error) {
+	info, err := getFormat(format)
+	if err != nil {
+		return nil, err
+	}
+	return &FileEmitter{
+		Format: format,
+		Path:   filepath.Join(dir, name+info.ext),
+		Perm:   perm,
+	}, nil
+}
+
+// NewStdoutEmitter 输出到标准输出
+func NewStdoutEmitter(format string) (*FileEmitter, error) {
+	if _, err := getFormat(format); err != nil {
+		return nil, err
+	}
+	return &FileEmitter{Format: format}, nil
+}
+
+func (e *FileEmitter) Emit(t *openapi3.T) (err error) {
+	info, err := getFormat(e.Format)
+	if err != nil {
+		return
+	}
+	var buf []byte
+	if buf, err = info.marshal(t); err != nil {
+		return newError(ErrorKindWrite, err)
+	}
+	return writeOutput(e.Path, e.Perm, e.Writer, buf)
+}
+
+// 输出内容，path为空时输出到writer，writer为nil时输出到标准输出
+func writeOutput(path string, perm fs.FileMode, writer io.Writer, buf []byte) (err error) {
+	if path == "" {
+		if writer == nil {
+			writer = os.Stdout
+		}
+		if _, err = writer.Write(buf); err != nil {
+			return newError(ErrorKindWrite, err)
+		}
+		return
+	}
+	if perm == 0 {
+		perm = defaultFilePerm
+	}
+	if dir := filepath.Dir(path); !isDir(dir) {
+		if err = os.MkdirAll(dir, 0755); err != nil {
+			return newError(ErrorKindWrite, err)
+		}
+	}
+	if err = os.WriteFile(path, buf, perm); err != nil {
+		return newError(ErrorKindWrite, fmt.Errorf("%v: %w", path, err))
+	}
+	return
+}
+
+// Emit 使用emitters依次输出文档，3.1版本的文档输出swagger2.0或者多个输出的文件路径相同时，在输出前返回错误
+func Emit(t *openapi3.T, emitters ...Emitter) (err error) {
+	pathMap := map[string]bool{}
+	for _, emitter := range emitters {
+		var path string
+		switch e := emitter.(type) {
+		case *FileEmitter:
+			path = e.Path
+		case *SwaggerEmitter:
+			if strings.HasPrefix(t.OpenAPI, "3.1") {
+				return newErrorMsg(ErrorKindValidate, errorSwagger31, t.OpenAPI)
+			}
+			path = e.Path
+		}
+		if path == "" {
+			continue
+		}
+		if path = filepath.Clean(path); pathMap[path] {
+			return newErrorMsg(ErrorKindWrite, "输出文件 %v 重复，多个输出会互相覆盖", path)
+		}
+		pathMap[path] = true
+	}
+	for _, emitter := range emitters {
+		if err = emitter.Emit(t); err != nil {
+			return
+		}
+	}
+	return
+}
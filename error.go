@@ -1,7 +1,115 @@
 package openapi
 
+import (
+	"errors"
+	"fmt"
+	"go/token"
+)
+
+const (
+	errorNotIn       = "值 %v 不在 [%v] 中"
+	errorType        = "值 %v 不是 %v 类型"
+	errorRepeat      = "字段 %v 的值 %v 重复"
+	errorStatus      = "值 %v 不是状态码，值包括 100-599、1XX-5XX 和 default"
+	errorMediaType   = "值 %v 不是媒体类型，格式为 类型/子类型，例如 application/json、image/*"
+	errorFormatValue = "值 %v 不符合类型 %v 的格式 %v，已忽略"
+	errorInline      = "字段 %v 的类型 %v 不是结构体，inline 无法展开，已作为普通字段"
+
+	errorRouteRepeat  = "路由 %v 重复"
+	errorOperationId  = "operationId %v 重复，已在 %v 中使用"
+	errorOnly31       = "只支持3.1版本，当前版本为 %v，已忽略"
+	errorSwagger31    = "swagger2.0只支持从3.0版本转换，当前版本为 %v"
+	errorInstanceName = "泛型实例 %v 和 %v 的名称 %v 重复，请修改结构体名称"
+)
+
+// 引用的组件或者结构体不存在
+var errRefNotFound = errors.New("引用不存在")
+
+// ErrorKind 错误类型
+type ErrorKind int
+
 const (
-	errorNotIn  = "值 %v 不在 [%v] 中"
-	errorType   = "值 %v 不是 %v 类型"
-	errorRepeat = "字段 %v 的值 %v 重复"
+	ErrorKindMod        ErrorKind = iota + 1 // go.mod 解析错误
+	ErrorKindParse                           // go 文件语法解析错误
+	ErrorKindAnnotation                      // 注释值错误
+	ErrorKindRoute                           // 路由错误，例如路由重复
+	ErrorKindSecurity                        // 验证字段未定义
+	ErrorKindValidate                        // 生成的文档验证失败
+	ErrorKindWrite                           // 文件写入错误
 )
+
+func (k ErrorKind) String() string {
+	switch k {
+	case ErrorKindMod:
+		return "mod"
+	case ErrorKindParse:
+		return "parse"
+	case ErrorKindAnnotation:
+		return "annotation"
+	case ErrorKindRoute:
+		return "route"
+	case ErrorKindSecurity:
+		return "security"
+	case ErrorKindValidate:
+		return "validate"
+	case ErrorKindWrite:
+		return "write"
+	}
+	return "unknown"
+}
+
+// Error 生成文档时返回的错误
+type Error struct {
+	Kind        ErrorKind      // 错误类型
+	Pos         token.Position // 错误位置，没有位置时为空
+	Msg         string         // 错误信息
+	Err         error          // 原始错误
+	Diagnostics Diagnostics    // 注释诊断信息，存在时Error()返回所有诊断
+}
+
+func (e *Error) Error() string {
+	if len(e.Diagnostics) > 0 {
+		return e.Msg + "\n" + e.Diagnostics.String()
+	}
+	msg := e.Msg
+	if msg == "" && e.Err != nil {
+		msg = e.Err.Error()
+	}
+	if e.Pos.IsValid() {
+		return fmt.Sprintf("%v: %v", e.Pos, msg)
+	}
+	return msg
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// IsErrorKind 判断错误是否是指定类型
+func IsErrorKind(err error, kind ErrorKind) bool {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.Kind == kind
+	}
+	return false
+}
+
+func newError(kind ErrorKind, err error) error {
+	if err == nil {
+		return nil
+	}
+	var e *Error
+	if errors.As(err, &e) {
+		return err
+	}
+	return &Error{Kind: kind, Err: err}
+}
+
+func newErrorMsg(kind ErrorKind, format string, args ...any) error {
+	return &Error{Kind: kind, Msg: fmt.Sprintf(format, args...)}
+}
+
+// 引用不存在的注释错误，诊断码为 CodeRefNotFound
+func newRefError(format string, args ...any) error {
+	return &Error{Kind: ErrorKindAnnotation, Msg: fmt.Sprintf(format, args...), Err: errRefNotFound}
+}
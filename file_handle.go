@@ -1,7 +1,6 @@
 package openapi
 
 import (
-	"os"
 	"path/filepath"
 	"strings"
 )
@@ -9,22 +8,22 @@ import (
 // 文件处理
 type fileHandle []string
 
-func (f *fileHandle) load(dir string) {
-	filePath, err := filepath.Abs(dir)
+func (f *fileHandle) load(fsys fsHandle, dir string) {
+	filePath, err := fsys.abs(dir)
 	if err != nil {
 		return
 	}
-	if !isDir(dir) {
+	if !fsIsDir(fsys, filePath) {
 		return
 	}
-	list, err := os.ReadDir(filePath)
+	list, err := fsys.readDir(filePath)
 	if err != nil {
 		return
 	}
 	for _, v := range list {
-		newPath := filepath.Join(filePath, v.Name())
+		newPath := fsys.join(filePath, v.Name())
 		if v.IsDir() {
-			f.load(newPath)
+			f.load(fsys, newPath)
 		} else if ext := filepath.Ext(v.Name()); ext == ".go" {
 			if !strings.HasSuffix(strings.TrimSuffix(v.Name(), ext), "_test") {
 				*f = append(*f, newPath)
@@ -0,0 +1,230 @@
+package openapi
+
+import (
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// 文件系统，统一本地文件系统、fs.FS和覆盖文件的读取
+type fsHandle interface {
+	abs(name string) (string, error)
+	rel(base, target string) (string, error)
+	join(elem ...string) string
+	readDir(name string) ([]fs.DirEntry, error)
+	readFile(name string) ([]byte, error)
+	stat(name string) (fs.FileInfo, error)
+}
+
+func newFsHandle(fsys fs.FS, overlay map[string][]byte) fsHandle {
+	var rs fsHandle = osFsHandle{}
+	if fsys != nil {
+		rs = ioFsHandle{fsys: fsys}
+	}
+	if len(overlay) > 0 {
+		rs = newOverlayFsHandle(rs, overlay)
+	}
+	return rs
+}
+
+func fsIsDir(fsys fsHandle, name string) bool {
+	fileInfo, err := fsys.stat(name)
+	if err != nil {
+		return false
+	}
+	return fileInfo.IsDir()
+}
+
+// 本地文件系统，路径为绝对路径
+type osFsHandle struct{}
+
+func (osFsHandle) abs(name string) (string, error) {
+	return filepath.Abs(name)
+}
+
+func (osFsHandle) rel(base, target string) (string, error) {
+	return filepath.Rel(base, target)
+}
+
+func (osFsHandle) join(elem ...string) string {
+	return filepath.Join(elem...)
+}
+
+func (osFsHandle) readDir(name string) ([]fs.DirEntry, error) {
+	return os.ReadDir(name)
+}
+
+func (osFsHandle) readFile(name string) ([]byte, error) {
+	return os.ReadFile(name)
+}
+
+func (osFsHandle) stat(name string) (fs.FileInfo, error) {
+	return os.Stat(name)
+}
+
+// fs.FS文件系统，路径为以/分割的相对路径
+type ioFsHandle struct {
+	fsys fs.FS
+}
+
+func (i ioFsHandle) abs(name string) (string, error) {
+	name = path.Clean(filepath.ToSlash(name))
+	name = strings.TrimPrefix(name, "/")
+	if name == "" {
+		name = "."
+	}
+	if !fs.ValidPath(name) {
+		return "", &fs.PathError{Op: "abs", Path: name, Err: fs.ErrInvalid}
+	}
+	return name, nil
+}
+
+func (i ioFsHandle) rel(base, target string) (string, error) {
+	if base == "." {
+		return target, nil
+	}
+	if target == base {
+		return ".", nil
+	}
+	if !strings.HasPrefix(target, base+"/") {
+		return "", &fs.PathError{Op: "rel", Path: target, Err: fs.ErrInvalid}
+	}
+	return strings.TrimPrefix(target, base+"/"), nil
+}
+
+func (i ioFsHandle) join(elem ...string) string {
+	return path.Join(elem...)
+}
+
+func (i ioFsHandle) readDir(name string) ([]fs.DirEntry, error) {
+	return fs.ReadDir(i.fsys, name)
+}
+
+func (i ioFsHandle) readFile(name string) ([]byte, error) {
+	return fs.ReadFile(i.fsys, name)
+}
+
+func (i ioFsHandle) stat(name string) (fs.FileInfo, error) {
+	return fs.Stat(i.fsys, name)
+}
+
+// 覆盖文件系统，优先读取覆盖的文件内容，用于未保存的文件
+type overlayFsHandle struct {
+	fsHandle
+	files map[string][]byte
+}
+
+func newOverlayFsHandle(base fsHandle, overlay map[string][]byte) *overlayFsHandle {
+	o := &overlayFsHandle{fsHandle: base, files: map[string][]byte{}}
+	for k, v := range overlay {
+		name, err := base.abs(k)
+		if err != nil {
+			continue
+		}
+		o.files[name] = v
+	}
+	return o
+}
+
+func (o *overlayFsHandle) readFile(name string) ([]byte, error) {
+	if buf, ok := o.files[name]; ok {
+		return buf, nil
+	}
+	return o.fsHandle.readFile(name)
+}
+
+func (o *overlayFsHandle) stat(name string) (fs.FileInfo, error) {
+	if buf, ok := o.files[name]; ok {
+		return overlayFileInfo{name: o.base(name), size: int64(len(buf))}, nil
+	}
+	fileInfo, err := o.fsHandle.stat(name)
+	if err != nil && o.hasDir(name) {
+		return overlayFileInfo{name: o.base(name), isDir: true}, nil
+	}
+	return fileInfo, err
+}
+
+func (o *overlayFsHandle) readDir(name string) ([]fs.DirEntry, error) {
+	list, err := o.fsHandle.readDir(name)
+	if err != nil && !o.hasDir(name) {
+		return nil, err
+	}
+	entryMap := map[string]fs.DirEntry{}
+	for _, v := range list {
+		entryMap[v.Name()] = v
+	}
+	for k, v := range o.files {
+		rel, err1 := o.rel(name, k)
+		if err1 != nil || rel == "." || strings.HasPrefix(rel, "..") {
+			continue
+		}
+		rel = filepath.ToSlash(rel)
+		first, other := getIndexFirst(rel, "/")
+		if other != "" {
+			// 覆盖文件在子目录中
+			if entryMap[first] == nil {
+				entryMap[first] = fs.FileInfoToDirEntry(overlayFileInfo{name: first, isDir: true})
+			}
+			continue
+		}
+		entryMap[first] = fs.FileInfoToDirEntry(overlayFileInfo{name: first, size: int64(len(v))})
+	}
+	list = list[:0]
+	for _, v := range entryMap {
+		list = append(list, v)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Name() < list[j].Name()
+	})
+	return list, nil
+}
+
+func (o *overlayFsHandle) hasDir(name string) bool {
+	for k := range o.files {
+		if rel, err := o.rel(name, k); err == nil && rel != "." && !strings.HasPrefix(rel, "..") {
+			return true
+		}
+	}
+	return false
+}
+
+func (o *overlayFsHandle) base(name string) string {
+	return filepath.Base(filepath.FromSlash(name))
+}
+
+type overlayFileInfo struct {
+	name  string
+	size  int64
+	isDir bool
+}
+
+func (f overlayFileInfo) Name() string {
+	return f.name
+}
+
+func (f overlayFileInfo) Size() int64 {
+	return f.size
+}
+
+func (f overlayFileInfo) Mode() fs.FileMode {
+	if f.isDir {
+		return fs.ModeDir | 0555
+	}
+	return 0444
+}
+
+func (f overlayFileInfo) ModTime() time.Time {
+	return time.Time{}
+}
+
+func (f overlayFileInfo) IsDir() bool {
+	return f.isDir
+}
+
+func (f overlayFileInfo) Sys() any {
+	return nil
+}
@@ -0,0 +1,143 @@
+package openapi
+
+import (
+	"context"
+	"strings"
+)
+
+// 一次文档生成的所有状态，不同的生成之间互不影响，可以并发执行
+type generator struct {
+	ctx            context.Context
+	opts           Options
+	fsys           fsHandle               // 项目文件系统
+	modFsys        fsHandle               // 模块缓存文件系统
+	rootDir        string                 // 项目根目录，fsys中的路径
+	projectModName string                 // 项目mod名称
+	modPathMap     modHandle              // mod名称对应的目录
+	workModNames   []string               // go.work中use的其他模块名称，和项目模块一样解析
+	diags          *diagnosticHandle      // 注释诊断收集器
+	version        string                 // 输出的openapi版本
+	typeMappings   map[string]TypeMapping // 内置和自定义的类型映射
+}
+
+func newGenerator(ctx context.Context, opts Options) *generator {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return &generator{
+		ctx:        ctx,
+		opts:       opts,
+		fsys:       newFsHandle(opts.FS, opts.Overlay),
+		modFsys:    newFsHandle(opts.ModCacheFS, nil), // 覆盖文件只用于项目，避免项目的相对路径覆盖模块缓存中的文件
+		modPathMap: modHandle{},
+		diags:      &diagnosticHandle{},
+	}
+}
+
+func (g *generator) loadVersion() (err error) {
+	var ok bool
+	if g.version, ok = openapiVersionMap[g.opts.OpenAPIVersion]; !ok {
+		return newErrorMsg(ErrorKindValidate, "openapi版本"+errorNotIn, g.opts.OpenAPIVersion,
+			OpenAPIVersion30+","+OpenAPIVersion31)
+	}
+	return
+}
+
+func (g *generator) loadFallback() (err error) {
+	if fallback := g.opts.FallbackResponse; fallback != nil && fallback.Status != "" && !isStatus(fallback.Status) {
+		return newErrorMsg(ErrorKindValidate, "默认返回"+errorStatus, fallback.Status)
+	}
+	return
+}
+
+// 合并内置和自定义的类型映射，自定义的优先
+func (g *generator) loadTypeMappings() (err error) {
+	g.typeMappings = cloneMap[map[string]TypeMapping](builtinTypeMappings)
+	mappings := map[string]TypeMapping{}
+	if g.opts.TypeMappingPath != "" {
+		// 配置文件和项目文件一样读取，支持FS和Overlay
+		var name string
+		var buf []byte
+		if name, err = g.fsys.abs(g.opts.TypeMappingPath); err == nil {
+			buf, err = g.fsys.readFile(name)
+		}
+		if err == nil {
+			mappings, err = parseTypeMappings(buf)
+		}
+		if err != nil {
+			return newErrorMsg(ErrorKindValidate, "类型映射文件 %v 解析失败：%v", g.opts.TypeMappingPath, err)
+		}
+	}
+	for k, v := range g.opts.TypeMappings {
+		mappings[k] = v
+	}
+	for _, k := range sortedKeys(mappings) {
+		v := mappings[k]
+		if inArray(v.Type, typeMappingTypes) == -1 {
+			return newErrorMsg(ErrorKindValidate, "类型映射 "+k+" 的类型"+errorNotIn, v.Type,
+				strings.Join(typeMappingTypes[1:], ","))
+		}
+		g.typeMappings[k] = v
+	}
+	return
+}
+
+func (g *generator) loadRequiredPolicy() (err error) {
+	if g.opts.RequiredPolicy == "" {
+		g.opts.RequiredPolicy = RequiredPolicyTag
+	}
+	if inArray(g.opts.RequiredPolicy, requiredPolicies) == -1 {
+		return newErrorMsg(ErrorKindValidate, "必填策略"+errorNotIn, g.opts.RequiredPolicy,
+			strings.Join(requiredPolicies, ","))
+	}
+	return
+}
+
+func (g *generator) loadMod() (err error) {
+	g.rootDir, err = g.fsys.abs(g.opts.RootDir)
+	if err != nil {
+		return newError(ErrorKindMod, err)
+	}
+	modCacheDir := "."
+	if g.opts.ModCacheFS == nil {
+		modCacheDir = g.modPathMap.modAbsPath()
+	}
+	// 使用fs.FS时不读取环境变量GOWORK，保证生成结果和本机环境无关
+	gowork := g.opts.GoWork
+	if gowork == "" && g.opts.FS == nil {
+		gowork = goEnv("GOWORK")
+	}
+	workPath := g.modPathMap.findWork(g.fsys, g.rootDir, gowork)
+	if workPath == "" {
+		g.projectModName, err = g.modPathMap.load(g.fsys, g.modFsys, modCacheDir, g.rootDir)
+		if err != nil {
+			return newError(ErrorKindMod, err)
+		}
+		return
+	}
+	modNames, modDirs, err := g.modPathMap.loadWork(g.fsys, g.modFsys, modCacheDir, workPath)
+	if err != nil {
+		return newError(ErrorKindMod, err)
+	}
+	for i, modName := range modNames {
+		if modDirs[i] == g.rootDir {
+			g.projectModName = modName
+			continue
+		}
+		g.workModNames = append(g.workModNames, modName)
+	}
+	if g.projectModName == "" {
+		return newErrorMsg(ErrorKindMod, "项目目录 %v 不在 %v 的 use 中", g.rootDir, workPath)
+	}
+	return
+}
+
+// 项目中的文件使用的解析器，注释错误会被收集
+func (g *generator) newAst() *astHandle {
+	return &astHandle{fsys: g.fsys, diags: g.diags, modDir: g.rootDir}
+}
+
+// go.work中其他模块的文件使用的解析器，和项目中的文件一样收集注释错误
+func (g *generator) newWorkAst(modName string) *astHandle {
+	return &astHandle{fsys: g.modPathMap[modName].fsys, diags: g.diags, modDir: g.modPathMap[modName].dir}
+}
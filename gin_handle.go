@@ -1,7 +1,6 @@
 package openapi
 
 import (
-	"log"
 	"os"
 	"path/filepath"
 	"regexp"
@@ -14,11 +13,11 @@ type ginHandle struct {
 	structAliasMap map[string]string
 }
 
-func (g *ginHandle) load(routesFunc []routeFuncInfo, routeDir string) {
+func (g *ginHandle) load(routesFunc []routeFuncInfo, routeDir string) (err error) {
 	if !isDir(routeDir) {
-		err := os.MkdirAll(routeDir, 0777)
+		err = os.MkdirAll(routeDir, 0777)
 		if err != nil {
-			log.Fatal(err)
+			return newError(ErrorKindWrite, err)
 		}
 	}
 	g.routesFunc = routesFunc
@@ -30,10 +29,11 @@ func (g *ginHandle) load(routesFunc []routeFuncInfo, routeDir string) {
 	content += g.generateStructDefine() + "\n\n"
 	content += g.generateRoutes() + "\n\n"
 	routePath := filepath.Join(routeDir, "commentsRoutes.go")
-	err := os.WriteFile(routePath, []byte(content), 0777)
+	err = os.WriteFile(routePath, []byte(content), 0777)
 	if err != nil {
-		log.Fatal(err)
+		return newError(ErrorKindWrite, err)
 	}
+	return
 }
 
 func (g *ginHandle) generateRoutes() string {
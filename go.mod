@@ -6,6 +6,7 @@ require (
 	github.com/getkin/kin-openapi v0.123.0
 	github.com/invopop/yaml v0.2.0
 	github.com/urfave/cli/v2 v2.27.1
+	golang.org/x/mod v0.14.0
 )
 
 require (
@@ -1,78 +1,258 @@
 package openapi
 
 import (
+	"go/build"
+	"golang.org/x/mod/modfile"
+	"golang.org/x/mod/module"
 	"os"
 	"path/filepath"
-	"regexp"
-	"runtime"
 	"strings"
 )
 
+// mod对应的目录和读取该目录使用的文件系统
+type modInfo struct {
+	dir  string
+	fsys fsHandle
+}
+
 // 处理mode便于获取引入的struct
-type modHandle map[string]string
+type modHandle map[string]*modInfo
 
-func (m *modHandle) load(filePath string) (modName string, err error) {
-	modFilePath := filepath.Join(filePath, "go.mod")
-	vendorFilePath := filepath.Join(filePath, "vendor")
+// fsys 读取项目的文件系统，modFsys 读取模块缓存的文件系统，modCacheDir 为modFsys中模块缓存的目录
+func (m *modHandle) load(fsys, modFsys fsHandle, modCacheDir, filePath string) (modName string, err error) {
+	return m.loadDir(fsys, modFsys, modCacheDir, filePath, nil, true)
+}
+
+// workReplaces 为go.work中的replace，优先于go.mod中的replace，allowVendor 为false时不使用vendor目录
+func (m *modHandle) loadDir(fsys, modFsys fsHandle, modCacheDir, filePath string, workReplaces []modReplace,
+	allowVendor bool) (modName string, err error) {
+	filePath, err = fsys.abs(filePath)
+	if err != nil {
+		return
+	}
+	modFilePath := fsys.join(filePath, "go.mod")
+	vendorFilePath := fsys.join(filePath, "vendor")
 	var modBuf []byte
-	modBuf, err = os.ReadFile(modFilePath)
+	modBuf, err = fsys.readFile(modFilePath)
 	if err != nil {
 		return
 	}
-	if isDir(vendorFilePath) {
-		modName = m.parseMod(string(modBuf), true, vendorFilePath)
+	if allowVendor && m.isVendor(fsys, vendorFilePath) {
+		modName, err = m.parseMod(modFilePath, modBuf, filePath, fsys, fsys, vendorFilePath, nil, true)
 	} else {
-		modName = m.parseMod(string(modBuf), false, m.modAbsPath())
+		modName, err = m.parseMod(modFilePath, modBuf, filePath, fsys, modFsys, modCacheDir, workReplaces, false)
+	}
+	if err != nil {
+		return
 	}
+	(*m)[modName] = &modInfo{dir: filePath, fsys: fsys}
 	return
 }
 
+// 查找go.work文件，gowork为off时不使用，为文件路径时使用该文件，否则从dir开始向上级目录查找
+func (m *modHandle) findWork(fsys fsHandle, dir, gowork string) string {
+	switch gowork {
+	case "off":
+		return ""
+	case "":
+	default:
+		return gowork
+	}
+	for {
+		workPath := fsys.join(dir, "go.work")
+		if fileInfo, err := fsys.stat(workPath); err == nil && !fileInfo.IsDir() {
+			return workPath
+		}
+		parent, err := fsys.abs(fsys.join(dir, ".."))
+		if err != nil || parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+// 解析go.work，use的模块和主模块一样解析，返回use的模块名称和目录
+func (m *modHandle) loadWork(fsys, modFsys fsHandle, modCacheDir, workPath string) (modNames, modDirs []string, err error) {
+	workPath, err = fsys.abs(workPath)
+	if err != nil {
+		return
+	}
+	buf, err := fsys.readFile(workPath)
+	if err != nil {
+		return
+	}
+	f, err := modfile.ParseWork(workPath, buf, nil)
+	if err != nil {
+		return
+	}
+	workDir := fsys.join(workPath, "..")
+	replaces := m.replaces(f.Replace, fsys, workDir)
+	for _, v := range f.Use {
+		dir := v.Path
+		if !filepath.IsAbs(dir) {
+			dir = fsys.join(workDir, dir)
+		}
+		var modName string
+		// 工作区中不使用vendor目录
+		if modName, err = m.loadDir(fsys, modFsys, modCacheDir, dir, replaces, false); err != nil {
+			return
+		}
+		modNames = append(modNames, modName)
+		modDirs = append(modDirs, (*m)[modName].dir)
+	}
+	// use的模块优先于go.mod中require的同名模块
+	for i, modName := range modNames {
+		(*m)[modName] = &modInfo{dir: modDirs[i], fsys: fsys}
+	}
+	return
+}
+
+// 和go命令一致，GOFLAGS中的-mod=vendor强制使用vendor，-mod=mod和-mod=readonly不使用vendor，否则存在vendor目录时使用
+func (m *modHandle) isVendor(fsys fsHandle, vendorDir string) bool {
+	for _, v := range strings.Fields(goEnv("GOFLAGS")) {
+		switch strings.TrimPrefix(v, "-") {
+		case "mod=vendor":
+			return true
+		case "mod=mod", "mod=readonly":
+			return false
+		}
+	}
+	return fsIsDir(fsys, vendorDir)
+}
+
+// 模块缓存目录，顺序为 GOMODCACHE，GOPATH中的第一个目录下的pkg/mod，默认的GOPATH下的pkg/mod
 func (m *modHandle) modAbsPath() string {
-	userPath := ""
-	if runtime.GOOS == "windows" {
-		userPath = os.Getenv("USERPROFILE")
+	if dir := goEnv("GOMODCACHE"); dir != "" {
+		return dir
+	}
+	gopath := goEnv("GOPATH")
+	if list := filepath.SplitList(gopath); len(list) > 0 && list[0] != "" {
+		gopath = list[0]
 	} else {
-		userPath = os.Getenv("HOME")
+		gopath = build.Default.GOPATH
 	}
-	return filepath.Join(userPath, "go", "pkg", "mod")
+	return filepath.Join(gopath, "pkg", "mod")
 }
 
-func (m *modHandle) parseMod(content string, isVendor bool, baseDir string) (modName string) {
-	// windows格式转linux格式
-	content = strings.ReplaceAll(content, "\r\n", "\n")
-	// mac格式转linux格式
-	content = strings.ReplaceAll(content, "\r", "\n")
-	// 获取当前mod
-	list := regexp.MustCompile("module( |\t)+(.*?)\n").FindAllStringSubmatch(content, -1)
-	if len(list) == 0 {
+// 解析go.mod，将require的模块解析为go命令使用的目录，projectFsys 读取replace的本地目录，fsys 读取baseDir
+func (m *modHandle) parseMod(modFilePath string, content []byte, projectDir string, projectFsys, fsys fsHandle,
+	baseDir string, workReplaces []modReplace, isVendor bool) (modName string, err error) {
+	f, err := modfile.Parse(modFilePath, content, nil)
+	if err != nil {
 		return
 	}
-	modName = list[0][2]
-	projectDir, _ := os.Getwd()
-	(*m)[modName] = projectDir
-	baseDir, _ = filepath.Abs(baseDir)
-	content = handleContentEnter(content)
-	content = regexp.MustCompile(`( |\t)+`).ReplaceAllString(content, " ")
-	// 解析require
-	reg := regexp.MustCompile(`require( |\t)+\(((?s).*?)\n\)`)
-	list = reg.FindAllStringSubmatch(content, -1)
-	for _, v := range list {
-		requireList := strings.Split(v[2], "\n")
-		for _, v1 := range requireList {
-			v1 = strings.Trim(v1, " ")
-			if v1 == "" {
-				continue
-			}
-			v1List := strings.Split(v1, " ")
-			if len(v1List) < 2 {
+	if f.Module == nil {
+		return "", newErrorMsg(ErrorKindMod, "%v 中不存在 module", modFilePath)
+	}
+	modName = f.Module.Mod.Path
+	baseDir, _ = fsys.abs(baseDir)
+	replaces := m.replaces(f.Replace, projectFsys, projectDir)
+	for _, v := range f.Require {
+		if isVendor {
+			// vendor目录中使用原模块路径，包括被replace的模块
+			(*m)[v.Mod.Path] = &modInfo{dir: fsys.join(baseDir, v.Mod.Path), fsys: fsys}
+			continue
+		}
+		mod := v.Mod
+		rep := m.replace(workReplaces, mod)
+		if rep == nil {
+			rep = m.replace(replaces, mod)
+		}
+		if rep != nil {
+			if rep.new.Version == "" {
+				if rep.dir != "" {
+					(*m)[v.Mod.Path] = &modInfo{dir: rep.dir, fsys: projectFsys}
+				}
 				continue
 			}
-			if isVendor {
-				(*m)[v1List[0]] = filepath.Join(baseDir, v1List[0])
-			} else {
-				(*m)[v1List[0]] = filepath.Join(baseDir, v1List[0]+"@"+v1List[1])
+			mod = rep.new
+		}
+		dir, err1 := m.cacheDir(mod)
+		if err1 != nil {
+			return "", newErrorMsg(ErrorKindMod, "%v: %v", modFilePath, err1)
+		}
+		(*m)[v.Mod.Path] = &modInfo{dir: fsys.join(baseDir, dir), fsys: fsys}
+	}
+	return
+}
+
+// 解析后的replace，dir 为本地目录
+type modReplace struct {
+	old module.Version
+	new module.Version
+	dir string
+}
+
+// 本地目录相对于baseDir，文件系统中无法访问的目录(例如fs.FS之外的目录)dir为空
+func (m *modHandle) replaces(list []*modfile.Replace, fsys fsHandle, baseDir string) (rs []modReplace) {
+	for _, v := range list {
+		rep := modReplace{old: v.Old, new: v.New}
+		if v.New.Version == "" {
+			dir := v.New.Path
+			if !filepath.IsAbs(dir) {
+				dir = fsys.join(baseDir, dir)
 			}
+			rep.dir, _ = fsys.abs(dir)
 		}
+		rs = append(rs, rep)
 	}
 	return
 }
+
+// 指定版本的replace优先于不指定版本的replace
+func (m *modHandle) replace(list []modReplace, mod module.Version) (rs *modReplace) {
+	for i, v := range list {
+		if v.old.Path != mod.Path {
+			continue
+		}
+		if v.old.Version == mod.Version {
+			return &list[i]
+		}
+		if v.old.Version == "" {
+			rs = &list[i]
+		}
+	}
+	return
+}
+
+// 模块在缓存中的目录，大写字母转义为!加小写字母
+func (m *modHandle) cacheDir(mod module.Version) (dir string, err error) {
+	path, err := module.EscapePath(mod.Path)
+	if err != nil {
+		return
+	}
+	version, err := module.EscapeVersion(mod.Version)
+	if err != nil {
+		return
+	}
+	return path + "@" + version, nil
+}
+
+// 获取go的环境变量，环境变量不存在时读取go env -w写入的配置文件
+func goEnv(key string) string {
+	if v, ok := os.LookupEnv(key); ok {
+		return v
+	}
+	envFile := os.Getenv("GOENV")
+	if envFile == "" {
+		dir, err := os.UserConfigDir()
+		if err != nil {
+			return ""
+		}
+		envFile = filepath.Join(dir, "go", "env")
+	}
+	if envFile == "off" {
+		return ""
+	}
+	buf, err := os.ReadFile(envFile)
+	if err != nil {
+		return ""
+	}
+	for _, line := range strings.Split(handleContentEnter(string(buf)), "\n") {
+		k, v, ok := strings.Cut(strings.TrimSpace(line), "=")
+		if ok && strings.TrimSpace(k) == key {
+			return strings.TrimSpace(v)
+		}
+	}
+	return ""
+}
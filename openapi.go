@@ -1,48 +1,133 @@
 package openapi
 
 import (
-	"encoding/json"
+	"context"
+	"github.com/getkin/kin-openapi/openapi3"
+	"io/fs"
 	"log"
-	"os"
 	"path/filepath"
 )
 
-func GenerateOpenAPI(rootDir, routeDir, docPath, outDir, ginGenerateRouteDir string) {
-	modPathMap = modHandle{}
-	var err error
-	projectModName, err = modPathMap.load(rootDir)
+// Options 生成文档的参数
+type Options struct {
+	RootDir  string // 项目根目录，需要包含go.mod文件
+	RouteDir string // 路由注释目录
+	DocPath  string // 文档注释文件地址
+
+	FS         fs.FS             // 项目文件系统，RootDir、RouteDir、DocPath 为其中的路径，为nil时使用本地文件系统
+	ModCacheFS fs.FS             // 模块缓存文件系统，根目录为模块缓存目录(GOMODCACHE)，为nil时使用本地模块缓存
+	Overlay    map[string][]byte // 覆盖项目文件系统中的文件内容，key为FS中的文件路径，用于未保存的文件，不影响模块缓存
+	GoWork     string            // go.work文件路径，off时不使用go.work，为空时FS为nil使用环境变量GOWORK，否则从RootDir向上查找
+
+	OpenAPIVersion     string                 // 输出的openapi版本，值包括 3.0.3(默认) 和 3.1.0，也可以简写为 3.0 和 3.1
+	FallbackResponse   *FallbackResponse      // 路由没有2XX、3XX和default返回时添加的返回，为nil时添加 200 Success
+	TypeMappings       map[string]TypeMapping // 自定义的类型映射，key为完整的类型名称，例如 github.com/google/uuid.UUID，覆盖内置的映射
+	TypeMappingPath    string                 // 类型映射配置文件，json或者yaml格式，FS中的路径，TypeMappings优先
+	RequiredPolicy     string                 // 结构体字段是否必填的判断方式，值包括 tag(默认) 和 omitempty
+	PointerNotNullable bool                   // 指针字段不输出nullable，默认指针字段可以为null
+	OnWarning          func(Diagnostic)       // 生成成功时按位置顺序回调所有警告
+}
+
+const (
+	RequiredPolicyTag       = "tag"       // 只有标签 required 的字段必填
+	RequiredPolicyOmitempty = "omitempty" // json标签没有omitempty的非指针字段也必填，标签 required 优先
+)
+
+var requiredPolicies = []string{RequiredPolicyTag, RequiredPolicyOmitempty}
+
+// FallbackResponse 路由没有声明成功的返回时添加的返回
+type FallbackResponse struct {
+	Status      string // 状态码，例如 200、2XX、default，为空时不添加
+	Description string // 返回描述，为空时为 Success
+}
+
+// Generate 根据注释生成openapi文档，不写入任何文件
+func Generate(ctx context.Context, opts Options) (*openapi3.T, error) {
+	openapi, err := generate(ctx, opts)
 	if err != nil {
-		log.Fatal(err)
+		return nil, err
+	}
+	return openapi.t, nil
+}
+
+func generate(ctx context.Context, opts Options) (openapi *openapiHandle, err error) {
+	g := newGenerator(ctx, opts)
+	if err = g.loadVersion(); err != nil {
+		return nil, err
+	}
+	if err = g.loadFallback(); err != nil {
+		return nil, err
+	}
+	if err = g.loadTypeMappings(); err != nil {
+		return nil, err
+	}
+	if err = g.loadRequiredPolicy(); err != nil {
+		return nil, err
+	}
+	if err = g.loadMod(); err != nil {
+		return nil, err
+	}
+	openapi = &openapiHandle{g: g}
+	if err = openapi.load(opts.RootDir, opts.RouteDir, opts.DocPath); err != nil {
+		return nil, err
+	}
+	if g.version == OpenAPIVersion31 {
+		(&openapi31Handle{}).convert(openapi)
 	}
-	openapi := &openapiHandle{}
-	openapi.load(rootDir, routeDir, docPath)
-	if !isDir(outDir) {
-		err = os.MkdirAll(outDir, 0777)
-		if err != nil {
-			log.Fatal(err)
+	if opts.OnWarning != nil {
+		for _, v := range g.diags.sorted() {
+			if v.Severity == SeverityWarning {
+				opts.OnWarning(v)
+			}
 		}
 	}
-	var buf []byte
-	// 生成yaml文档
-	buf, err = yamlMarshal(openapi.t)
+	return
+}
+
+// Config 生成文档后的输出配置
+type Config struct {
+	Options
+	Emitters    []Emitter // 文档输出，为空时不输出
+	GinRouteDir string    // gin路由文件生成目录，为空时不生成
+}
+
+// Run 生成文档，使用Emitters输出，并生成gin路由文件
+func Run(ctx context.Context, cfg Config) (*openapi3.T, error) {
+	openapi, err := generate(ctx, cfg.Options)
 	if err != nil {
-		log.Fatal(err)
+		return nil, err
 	}
-	err = os.WriteFile(filepath.Join(outDir, "openapi.yaml"), buf, 0777)
-	if err != nil {
-		log.Fatal(err)
+	if err = Emit(openapi.t, cfg.Emitters...); err != nil {
+		return nil, err
 	}
-	// 生成json文档
-	buf, err = json.MarshalIndent(&openapi.t, "", "    ")
-	if err != nil {
-		log.Fatal(err)
+	if cfg.GinRouteDir != "" {
+		gins := ginHandle{}
+		if err = gins.load(openapi.routesFunc, cfg.GinRouteDir); err != nil {
+			return nil, err
+		}
 	}
-	err = os.WriteFile(filepath.Join(outDir, "openapi.json"), buf, 0777)
+	return openapi.t, nil
+}
+
+// DefaultEmitters 在outDir目录中生成 openapi.yaml 和 openapi.json 文档
+func DefaultEmitters(outDir string) []Emitter {
+	return []Emitter{
+		&FileEmitter{Format: FormatYAML, Path: filepath.Join(outDir, "openapi.yaml")},
+		&FileEmitter{Format: FormatJSON, Path: filepath.Join(outDir, "openapi.json")},
+	}
+}
+
+func GenerateOpenAPI(rootDir, routeDir, docPath, outDir, ginGenerateRouteDir string) {
+	_, err := Run(context.Background(), Config{
+		Options: Options{
+			RootDir:  rootDir,
+			RouteDir: routeDir,
+			DocPath:  docPath,
+		},
+		Emitters:    DefaultEmitters(outDir),
+		GinRouteDir: ginGenerateRouteDir,
+	})
 	if err != nil {
 		log.Fatal(err)
 	}
-	if ginGenerateRouteDir != "" {
-		gins := ginHandle{}
-		gins.load(openapi.routesFunc, ginGenerateRouteDir)
-	}
 }
@@ -0,0 +1,210 @@
+package openapi
+
+import (
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// 将生成的3.0文档转换为3.1文档
+// kin-openapi只支持3.0的结构，3.1中新增或者改变的字段通过Extensions输出，因此必须在验证之后转换
+type openapi31Handle struct {
+	visited map[*openapi3.Schema]bool
+}
+
+func (h *openapi31Handle) convert(o *openapiHandle) {
+	t := o.t
+	h.visited = map[*openapi3.Schema]bool{}
+	t.OpenAPI = OpenAPIVersion31
+	if t.Info != nil {
+		if o.infoSummary != "" {
+			t.Info.Extensions = setExtension(t.Info.Extensions, "summary", o.infoSummary)
+		}
+		if t.Info.License != nil && o.licenseIdentifier != "" {
+			t.Info.License.Extensions = setExtension(t.Info.License.Extensions, "identifier", o.licenseIdentifier)
+		}
+	}
+	if len(o.webhooks) > 0 {
+		for _, pathItem := range o.webhooks {
+			h.pathItem(pathItem)
+		}
+		t.Extensions = setExtension(t.Extensions, "webhooks", o.webhooks)
+	}
+	if t.Components != nil {
+		for _, v := range t.Components.Schemas {
+			h.schemaRef(v)
+		}
+		for _, v := range t.Components.Parameters {
+			h.parameterRef(v)
+		}
+		for _, v := range t.Components.Headers {
+			h.headerRef(v)
+		}
+		for _, v := range t.Components.RequestBodies {
+			h.requestBodyRef(v)
+		}
+		for _, v := range t.Components.Responses {
+			h.responseRef(v)
+		}
+	}
+	if t.Paths != nil {
+		for _, pathItem := range t.Paths.Map() {
+			h.pathItem(pathItem)
+		}
+	}
+}
+
+func (h *openapi31Handle) pathItem(pathItem *openapi3.PathItem) {
+	if pathItem == nil {
+		return
+	}
+	for _, v := range pathItem.Parameters {
+		h.parameterRef(v)
+	}
+	for _, operation := range pathItem.Operations() {
+		for _, v := range operation.Parameters {
+			h.parameterRef(v)
+		}
+		h.requestBodyRef(operation.RequestBody)
+		if operation.Responses != nil {
+			for _, v := range operation.Responses.Map() {
+				h.responseRef(v)
+			}
+		}
+		for _, callback := range operation.Callbacks {
+			if callback == nil || callback.Value == nil {
+				continue
+			}
+			for _, v := range callback.Value.Map() {
+				h.pathItem(v)
+			}
+		}
+	}
+}
+
+func (h *openapi31Handle) parameterRef(ref *openapi3.ParameterRef) {
+	if ref == nil || ref.Ref != "" || ref.Value == nil {
+		return
+	}
+	h.schemaRef(ref.Value.Schema)
+	h.content(ref.Value.Content)
+}
+
+func (h *openapi31Handle) headerRef(ref *openapi3.HeaderRef) {
+	if ref == nil || ref.Ref != "" || ref.Value == nil {
+		return
+	}
+	h.schemaRef(ref.Value.Schema)
+	h.content(ref.Value.Content)
+}
+
+func (h *openapi31Handle) requestBodyRef(ref *openapi3.RequestBodyRef) {
+	if ref == nil || ref.Ref != "" || ref.Value == nil {
+		return
+	}
+	h.content(ref.Value.Content)
+}
+
+func (h *openapi31Handle) responseRef(ref *openapi3.ResponseRef) {
+	if ref == nil || ref.Ref != "" || ref.Value == nil {
+		return
+	}
+	for _, v := range ref.Value.Headers {
+		h.headerRef(v)
+	}
+	h.content(ref.Value.Content)
+}
+
+func (h *openapi31Handle) content(content openapi3.Content) {
+	for _, v := range content {
+		if v != nil {
+			h.schemaRef(v.Schema)
+		}
+	}
+}
+
+func (h *openapi31Handle) schemaRef(ref *openapi3.SchemaRef) {
+	if ref == nil || ref.Value == nil {
+		return
+	}
+	schema := ref.Value
+	if h.visited[schema] {
+		return
+	}
+	h.visited[schema] = true
+	if ref.Ref != "" {
+		// 3.1中$ref可以有同级字段，保留描述等信息
+		if !h.hasSibling(schema) {
+			return
+		}
+		schema.Extensions = setExtension(schema.Extensions, "$ref", ref.Ref)
+		ref.Ref = ""
+	}
+	h.schema(schema)
+}
+
+func (h *openapi31Handle) schema(schema *openapi3.Schema) {
+	for _, v := range schema.Properties {
+		h.schemaRef(v)
+	}
+	h.schemaRef(schema.Items)
+	h.schemaRef(schema.AdditionalProperties.Schema)
+	h.schemaRef(schema.Not)
+	for _, list := range []openapi3.SchemaRefs{schema.OneOf, schema.AnyOf, schema.AllOf} {
+		for _, v := range list {
+			h.schemaRef(v)
+		}
+	}
+	// example 改为 examples 数组
+	if schema.Example != nil {
+		schema.Extensions = setExtension(schema.Extensions, "examples", []interface{}{schema.Example})
+		schema.Example = nil
+	}
+	// map的key类型使用propertyNames说明
+	if keyType, ok := schema.Extensions[mapKeyTypeExtension].(string); ok && mapKeyPatterns[keyType] != "" {
+		schema.Extensions = setExtension(schema.Extensions, "propertyNames", map[string]interface{}{
+			"type":    "string",
+			"pattern": mapKeyPatterns[keyType],
+		})
+	}
+	// nullable 改为类型数组
+	if schema.Nullable {
+		schema.Nullable = false
+		if ref, ok := schema.Extensions["$ref"]; ok {
+			delete(schema.Extensions, "$ref")
+			schema.AnyOf = append(schema.AnyOf, &openapi3.SchemaRef{Value: &openapi3.Schema{
+				Extensions: map[string]interface{}{"$ref": ref},
+			}}, &openapi3.SchemaRef{Value: &openapi3.Schema{
+				Extensions: map[string]interface{}{"type": "null"},
+			}})
+		} else if schema.Type != "" {
+			schema.Extensions = setExtension(schema.Extensions, "type", []string{schema.Type, "null"})
+			schema.Type = ""
+		} else if len(schema.AllOf) == 1 && schema.AllOf[0].Ref != "" {
+			// 3.0中用allOf包装的$ref
+			schema.AnyOf = append(schema.AnyOf, schema.AllOf[0], &openapi3.SchemaRef{Value: &openapi3.Schema{
+				Extensions: map[string]interface{}{"type": "null"},
+			}})
+			schema.AllOf = nil
+		}
+	}
+}
+
+// map的key类型对应的属性名正则
+var mapKeyPatterns = map[string]string{
+	"integer": `^-?[0-9]+$`,
+	"number":  `^-?[0-9]+(\.[0-9]+)?$`,
+	"boolean": `^(true|false)$`,
+}
+
+// $ref同级是否有需要保留的字段
+func (h *openapi31Handle) hasSibling(schema *openapi3.Schema) bool {
+	return schema.Description != "" || schema.Nullable || schema.Deprecated || schema.Example != nil ||
+		schema.Default != nil || schema.ReadOnly || schema.WriteOnly || schema.Title != ""
+}
+
+func setExtension(extensions map[string]interface{}, key string, value interface{}) map[string]interface{} {
+	if extensions == nil {
+		extensions = map[string]interface{}{}
+	}
+	extensions[key] = value
+	return extensions
+}
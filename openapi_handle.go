@@ -1,27 +1,41 @@
 package openapi
 
 import (
-	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"github.com/getkin/kin-openapi/openapi3"
-	"log"
+	"go/token"
+	"net/http"
 	"path/filepath"
 	"strconv"
 	"strings"
+	"unicode"
 )
 
 type openapiHandle struct {
+	g             *generator
 	t             *openapi3.T
 	structs       map[string]*structInfo
 	routesFunc    []routeFuncInfo
 	schemas       openapi3.Schemas
 	importStructs map[string]bool
 	sameStructs   map[string]string
+	genericParams map[string][]string
+	schemaNames   map[string]*structInfo // schema名称对应的结构体，用于判断泛型实例的名称是否重复
+	valueWarnings map[string]bool        // 已经警告过的字段，同一个字段只警告一次
+	namedOps      []routeOperation       // 指定了operationId的回调和webhook操作，和路由一起检查是否重复
 	globalRoutes  map[string]interface{}
+	componentDocs map[string]interface{}
+	webhookDocs   map[string]interface{}
+	docPath       string
+	// 3.1版本才有的字段
+	infoSummary       string
+	licenseIdentifier string
+	webhooks          map[string]*openapi3.PathItem
 }
 
-func (o *openapiHandle) load(rootDir, routeDir, docPath string) {
+func (o *openapiHandle) load(rootDir, routeDir, docPath string) (err error) {
 	o.t = &openapi3.T{
 		OpenAPI: Version,
 	}
@@ -29,34 +43,50 @@ func (o *openapiHandle) load(rootDir, routeDir, docPath string) {
 	o.schemas = map[string]*openapi3.SchemaRef{}
 	o.importStructs = map[string]bool{}
 	o.sameStructs = map[string]string{}
+	o.genericParams = map[string][]string{}
+	o.schemaNames = map[string]*structInfo{}
+	o.valueWarnings = map[string]bool{}
 	o.globalRoutes = map[string]interface{}{}
-	o.generateDoc(docPath)
-	o.generateRoute(rootDir, routeDir)
-	if err := o.t.Validate(context.Background()); err != nil {
-		log.Fatal(err)
+	o.componentDocs = map[string]interface{}{}
+	o.webhookDocs = map[string]interface{}{}
+	o.docPath = docPath
+	if err = o.generateDoc(docPath); err != nil {
+		return
+	}
+	if err = o.generateRoute(rootDir, routeDir); err != nil {
+		return
 	}
+	// 所有文件解析完成后统一返回注释错误
+	if err = o.g.diags.error(); err != nil {
+		return
+	}
+	if err = o.t.Validate(o.g.ctx); err != nil {
+		return newError(ErrorKindValidate, err)
+	}
+	return
 }
 
-func (o *openapiHandle) handleRootDirStructs(rootDir string) {
+func (o *openapiHandle) handleRootDirStructs(rootDir string) (err error) {
 	fileList := fileHandle{}
-	fileList.load(rootDir)
-	for _, filePath := range fileList {
-		asts := new(astHandle)
-		err := asts.load(filePath, projectModName, astLoadTypeStruct)
-		if err != nil {
-			log.Fatal(err)
-		}
-		for k, v := range asts.structs {
-			o.structs[k] = v
-		}
-		for k, v := range asts.sameStructs {
-			o.sameStructs[k] = v
+	fileList.load(o.g.fsys, rootDir)
+	if err = o.loadStructs(fileList, o.g.projectModName, o.g.newAst); err != nil {
+		return
+	}
+	// go.work中的其他模块和项目一样解析
+	for _, modName := range o.g.workModNames {
+		modName := modName
+		fileList = fileHandle{}
+		fileList.load(o.g.modPathMap[modName].fsys, o.g.modPathMap[modName].dir)
+		if err = o.loadStructs(fileList, modName, func() *astHandle {
+			return o.g.newWorkAst(modName)
+		}); err != nil {
+			return
 		}
 	}
 	// 项目中不添加mod名称的引入，结构体的包+结构体名称不能出现重复，否则原样输出
 	repeatStructs := map[string][]string{}
 	for k, _ := range o.structs {
-		if strings.HasPrefix(k, projectModName) {
+		if o.isProjectStruct(k) {
 			repeatStructs[filepath.Base(k)] = append(repeatStructs[filepath.Base(k)], k)
 		}
 	}
@@ -65,23 +95,77 @@ func (o *openapiHandle) handleRootDirStructs(rootDir string) {
 			continue
 		}
 		o.structs[k] = o.structs[v[0]]
+		if params := o.genericParams[v[0]]; params != nil {
+			o.genericParams[k] = params
+		}
+	}
+	return
+}
+
+func (o *openapiHandle) loadStructs(fileList fileHandle, modName string, newAst func() *astHandle) (err error) {
+	for _, filePath := range fileList {
+		if err = o.g.ctx.Err(); err != nil {
+			return
+		}
+		asts := newAst()
+		err = asts.load(filePath, modName, astLoadTypeStruct)
+		if err != nil {
+			return
+		}
+		for k, v := range asts.structs {
+			o.structs[k] = v
+		}
+		for k, v := range asts.sameStructs {
+			o.sameStructs[k] = v
+		}
+		for k, v := range asts.genericParams {
+			o.genericParams[k] = v
+		}
+	}
+	return
+}
+
+// 项目和go.work中其他模块的结构体
+func (o *openapiHandle) isProjectStruct(name string) bool {
+	if strings.HasPrefix(name, o.g.projectModName) {
+		return true
 	}
+	for _, modName := range o.g.workModNames {
+		if strings.HasPrefix(name, modName) {
+			return true
+		}
+	}
+	return false
 }
 
 func (o *openapiHandle) addImportStruct(v interface{}) {
 	vMap, _ := v.(map[string]interface{})
 	bodyMap, _ := vMap["@body"].(map[string]interface{})
 	content, _ := bodyMap["content"].(string)
-	if content != "" {
-		o.importStructs[strings.TrimPrefix(content, "[]")] = true
+	o.addImportType(content)
+	for _, k := range []string{"@res", "@param", "@callback.body", "@callback.res", "@webhooks.body", "@webhooks.res"} {
+		vList, _ := vMap[k].([]map[string]interface{})
+		for _, v1Map := range vList {
+			content, _ = v1Map["content"].(string)
+			o.addImportType(content)
+		}
 	}
-	resList, _ := vMap["@res"].([]map[string]interface{})
-	for _, v1Map := range resList {
-		content, _ = v1Map["content"].(string)
-		if content != "" {
-			o.importStructs[strings.TrimPrefix(content, "[]")] = true
+}
+
+// 注释中使用的类型，泛型实例同时引入泛型类型和类型参数
+func (o *openapiHandle) addImportType(types string) {
+	types = strings.TrimPrefix(types, "[]")
+	if types == "" {
+		return
+	}
+	if base, args, ok := genericType(types); ok {
+		o.importStructs[base] = true
+		for _, arg := range args {
+			o.addImportType(arg)
 		}
+		return
 	}
+	o.importStructs[types] = true
 }
 
 func (o *openapiHandle) handleImportStruct() {
@@ -93,7 +177,7 @@ func (o *openapiHandle) handleImportStruct() {
 	}
 	// 对比mod文件获取引入文件
 	fileMap := map[string]bool{}
-	for k, _ := range modPathMap {
+	for k, _ := range o.g.modPathMap {
 		for k1, _ := range o.importStructs {
 			other := strings.TrimPrefix(k1, k)
 			if other == k1 || fileMap[k] {
@@ -105,24 +189,48 @@ func (o *openapiHandle) handleImportStruct() {
 	fileModList := map[string][]string{}
 	for k, _ := range fileMap {
 		files := fileHandle{}
-		files.load(modPathMap[k])
+		files.load(o.g.modPathMap[k].fsys, o.g.modPathMap[k].dir)
 		fileModList[k] = append(fileModList[k], files...)
 	}
 	for k, vList := range fileModList {
 		for _, v1 := range vList {
-			structHandle := new(astHandle)
-			_ = structHandle.load(v1, k, astLoadTypeStruct, modPathMap[k])
+			structHandle := &astHandle{fsys: o.g.modPathMap[k].fsys}
+			_ = structHandle.load(v1, k, astLoadTypeStruct, o.g.modPathMap[k].dir)
 			for k2, v2 := range structHandle.structs {
 				o.structs[k2] = v2
 			}
 			for k2, v2 := range structHandle.sameStructs {
 				o.sameStructs[k2] = v2
 			}
+			for k2, v2 := range structHandle.genericParams {
+				o.genericParams[k2] = v2
+			}
 		}
 	}
 }
 
 func (o *openapiHandle) handleNoStructFieldName() {
+	for k, v := range o.structs {
+		if o.genericParams[k] == nil {
+			o.schemaNames[v.name] = v
+		}
+	}
+	doneMap := map[*structInfo]bool{}
+	for _, k := range sortedKeys(o.structs) {
+		// 泛型类型在实例化时展开
+		if o.genericParams[k] != nil {
+			continue
+		}
+		o.flattenStruct(o.structs[k], doneMap, map[*structInfo]bool{})
+	}
+}
+
+// 展开匿名嵌入的结构体，先展开子结构体，保证多层嵌入的结果和遍历顺序无关
+func (o *openapiHandle) flattenStruct(strInfo *structInfo, doneMap, parentMap map[*structInfo]bool) {
+	if doneMap[strInfo] || parentMap[strInfo] {
+		return
+	}
+	parentMap[strInfo] = true
 	inBool := func(v structField, list []structField) bool {
 		for i := range list {
 			if list[i].fieldName == v.fieldName {
@@ -131,56 +239,235 @@ func (o *openapiHandle) handleNoStructFieldName() {
 		}
 		return false
 	}
-	for k, v := range o.structs {
-		var fieldNameList []structField
-		for _, fieldInfo := range v.list {
-			if fieldInfo.fieldName == "" {
-				childStruct := o.structs[fieldInfo.fieldType]
-				if childStruct != nil {
-					for _, v1 := range childStruct.list {
-						if !inBool(v1, fieldNameList) {
-							fieldNameList = append(fieldNameList, v1)
-						}
+	var fieldNameList []structField
+	for _, fieldInfo := range strInfo.list {
+		if fieldInfo.inline {
+			// 只有结构体和结构体指针可以展开，其他类型作为普通字段，生成schema时警告
+			o.instantiate(fieldInfo.fieldType)
+			if o.structs[fieldInfo.fieldType] != nil {
+				fieldInfo.fieldName = ""
+			}
+		}
+		if fieldInfo.fieldName == "" {
+			o.instantiate(fieldInfo.fieldType)
+			childStruct := o.structs[fieldInfo.fieldType]
+			if childStruct != nil {
+				o.flattenStruct(childStruct, doneMap, parentMap)
+				for _, v1 := range childStruct.list {
+					if v1.fieldName != "" && !inBool(v1, fieldNameList) {
+						fieldNameList = append(fieldNameList, v1)
 					}
 				}
-			} else {
-				fieldNameList = append(fieldNameList, fieldInfo)
 			}
+		} else {
+			fieldNameList = append(fieldNameList, fieldInfo)
 		}
-		o.structs[k].list = fieldNameList
 	}
+	strInfo.list = fieldNameList
+	doneMap[strInfo] = true
+}
+
+// 泛型类型实例化，例如 pkg.Result[pkg.User]，字段类型中的类型参数替换为实际类型
+func (o *openapiHandle) instantiate(types string) {
+	base, args, ok := genericType(types)
+	if !ok || o.structs[types] != nil || o.sameStructs[types] != "" {
+		return
+	}
+	params := o.genericParams[base]
+	if len(params) == 0 || len(params) != len(args) {
+		return
+	}
+	var oldNew []string
+	for i, param := range params {
+		oldNew = append(oldNew, "{"+param+"}", args[i])
+	}
+	replacer := strings.NewReplacer(oldNew...)
+	if sameTypes := o.sameStructs[base]; sameTypes != "" {
+		o.sameStructs[types] = replacer.Replace(sameTypes)
+		return
+	}
+	strInfo := o.structs[base]
+	if strInfo == nil {
+		return
+	}
+	instanceOf := o.canonicalType(types)
+	instInfo := &structInfo{name: o.instanceName(strInfo.name, args, instanceOf), comment: strInfo.comment, instanceOf: instanceOf}
+	// 同一个实例使用不同的写法时共用结构体，例如 generic.Result[generic.User] 和完整包路径
+	if same := o.schemaNames[instInfo.name]; same != nil {
+		o.structs[types] = same
+		return
+	}
+	for _, field := range strInfo.list {
+		field.fieldType = replacer.Replace(field.fieldType)
+		instInfo.list = append(instInfo.list, field)
+	}
+	o.structs[types] = instInfo
+	o.schemaNames[instInfo.name] = instInfo
+	o.flattenStruct(instInfo, map[*structInfo]bool{}, map[*structInfo]bool{})
+}
+
+// 泛型实例的类型名称和类型参数，例如 pkg.Result[pkg.User] 返回 pkg.Result 和 [pkg.User]
+func genericType(types string) (base string, args []string, ok bool) {
+	idx := strings.Index(types, "[")
+	if idx <= 0 || strings.HasPrefix(types, "map[") || !strings.HasSuffix(types, "]") {
+		return
+	}
+	depth, start := 0, idx+1
+	for i := start; i < len(types)-1; i++ {
+		switch types[i] {
+		case '[':
+			depth++
+		case ']':
+			depth--
+		case ',':
+			if depth == 0 {
+				args = append(args, strings.TrimSpace(types[start:i]))
+				start = i + 1
+			}
+		}
+	}
+	args = append(args, strings.TrimSpace(types[start:len(types)-1]))
+	return types[:idx], args, true
+}
+
+// 实例名称为泛型名称加类型参数名称，和泛型类型不同包的结构体加上包名，例如 Result[User] 为 ResultUser，Result[a.User] 为 ResultAUser
+// 名称只由类型决定，和解析顺序无关；和结构体重名时所有结构体参数都加上包名，例如 ResultGenericUser，和其他实例重名时报错
+func (o *openapiHandle) instanceName(baseName string, args []string, instanceOf string) (name string) {
+	pkgName := baseName[:strings.LastIndex(baseName, ".")+1]
+	var short, qualified string
+	for _, arg := range args {
+		short += o.genericName(arg, pkgName)
+		qualified += o.genericName(arg, "")
+	}
+	name = baseName + short
+	if same := o.schemaNames[name]; same != nil && same.instanceOf == "" {
+		name = baseName + qualified
+	}
+	if same := o.schemaNames[name]; same != nil && same.instanceOf != instanceOf {
+		o.g.diags.add(SeverityError, CodeRepeat, "schema", fmt.Sprintf(errorInstanceName, instanceOf,
+			same.instanceOf, name), token.Position{})
+		name += strconv.Itoa(len(o.schemaNames))
+	}
+	return
+}
+
+// 完整的类型名称，结构体使用生成的名称，用于比较不同写法的类型
+func (o *openapiHandle) canonicalType(types string) string {
+	if itemTypes := strings.TrimPrefix(types, "[]"); itemTypes != types {
+		return "[]" + o.canonicalType(itemTypes)
+	}
+	if mapTypes := strings.TrimPrefix(types, "map["); mapTypes != types {
+		keyTypes, valueTypes := getIndexFirst(mapTypes, "]")
+		return "map[" + o.canonicalType(keyTypes) + "]" + o.canonicalType(valueTypes)
+	}
+	if base, args, ok := genericType(types); ok {
+		for i, arg := range args {
+			args[i] = o.canonicalType(arg)
+		}
+		return o.canonicalType(base) + "[" + strings.Join(args, thirdListCutSign) + "]"
+	}
+	if strInfo := o.structs[types]; strInfo != nil {
+		if strInfo.instanceOf != "" {
+			return strInfo.instanceOf
+		}
+		return strInfo.name
+	}
+	return types
+}
+
+// 类型参数在实例名称中的名称，例如 []pkg.User 为 UserList，map[string]int 为 IntMap
+// 结构体不在pkgName包中时加上包名，例如 a.User 为 AUser，pkgName为空时结构体都加上包名
+func (o *openapiHandle) genericName(types string, pkgName string) string {
+	if itemTypes := strings.TrimPrefix(types, "[]"); itemTypes != types {
+		return o.genericName(itemTypes, pkgName) + "List"
+	}
+	if mapTypes := strings.TrimPrefix(types, "map["); mapTypes != types {
+		_, valueTypes := getIndexFirst(mapTypes, "]")
+		return o.genericName(valueTypes, pkgName) + "Map"
+	}
+	if base, args, ok := genericType(types); ok {
+		name := o.genericName(base, pkgName)
+		for _, arg := range args {
+			name += o.genericName(arg, pkgName)
+		}
+		return name
+	}
+	if types == anyType {
+		return "Any"
+	}
+	name := upperFirst(types[strings.LastIndex(types, ".")+1:])
+	if strInfo := o.structs[types]; strInfo != nil {
+		idx := strings.LastIndex(strInfo.name, ".")
+		if idx > 0 && strInfo.name[:idx+1] != pkgName {
+			pkgPath := strInfo.name[:idx]
+			name = upperFirst(pkgPath[strings.LastIndex(pkgPath, ".")+1:]) + name
+		}
+	}
+	return name
+}
+
+func upperFirst(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToUpper(s[:1]) + s[1:]
 }
 
-func (o *openapiHandle) generateRoute(rootDir, routeDir string) {
+func (o *openapiHandle) generateRoute(rootDir, routeDir string) (err error) {
 	fileList := fileHandle{}
-	fileList.load(routeDir)
+	fileList.load(o.g.fsys, routeDir)
 	routes := map[string]map[string]interface{}{}
+	routesPos := map[string]token.Position{}
+	// 按照文件和注释的顺序处理路由，保证输出稳定
+	var routeKeys []string
 	for _, filePath := range fileList {
-		asts := new(astHandle)
-		err := asts.load(filePath, projectModName, astLoadTypeRoute|astLoadTypeStruct)
+		if err = o.g.ctx.Err(); err != nil {
+			return
+		}
+		asts := o.g.newAst()
+		err = asts.load(filePath, o.g.projectModName, astLoadTypeRoute|astLoadTypeStruct)
 		if err != nil {
-			log.Fatal(err)
+			return
 		}
-		for k, v := range asts.routes {
+		for _, k := range asts.routeKeys {
+			v := asts.routes[k]
 			if routes[k] != nil {
-				log.Fatal("路由重复")
+				o.g.diags.add(SeverityError, CodeRouteRepeat, "@router", fmt.Sprintf(errorRouteRepeat, k), asts.routesPos[k])
+				continue
 			}
 			routes[k] = v
+			routesPos[k] = asts.routesPos[k]
+			routeKeys = append(routeKeys, k)
 			// 增加路由引入结构体
 			o.addImportStruct(v)
 		}
 		o.routesFunc = append(o.routesFunc, asts.routesFunc...)
 	}
-	if len(routes) == 0 {
+	if len(routes) == 0 && len(o.componentDocs) == 0 && len(o.webhookDocs) == 0 {
+		return
+	}
+	if err = o.handleRootDirStructs(rootDir); err != nil {
 		return
 	}
-	o.handleRootDirStructs(rootDir)
 	o.handleImportStruct()
 	o.handleNoStructFieldName()
+	if err = o.setComponents(); err != nil {
+		return
+	}
+	if err = o.setWebhooks(); err != nil {
+		return
+	}
 	if o.t.Paths == nil {
 		o.t.Paths = &openapi3.Paths{}
 	}
-	for k, vMap := range routes {
+	funcMap := map[string]routeFuncInfo{}
+	for _, v := range o.routesFunc {
+		funcMap[v.path+"_"+v.method] = v
+	}
+	var operations []routeOperation
+	for _, k := range routeKeys {
+		// 多个@router共用同一个注释，复制后再修改
+		vMap := cloneMap(routes[k])
 		vList := strings.Split(k, "_")
 		path := strings.Join(vList[:len(vList)-1], "_")
 		method := vList[len(vList)-1]
@@ -220,26 +507,39 @@ func (o *openapiHandle) generateRoute(rootDir, routeDir string) {
 			}
 		}
 		// 处理通用路由
-		for k1, v1 := range o.globalRoutes {
+		for _, k1 := range sortedKeys(o.globalRoutes) {
+			v1 := o.globalRoutes[k1]
 			if vMap[k1] != nil {
 				switch setData := vMap[k1].(type) {
 				case []map[string]interface{}:
 					v1List, _ := v1.([]map[string]interface{})
-					setData = append(setData, v1List...)
-					vMap[k1] = setData
+					vMap[k1] = append(append([]map[string]interface{}{}, setData...), v1List...)
 				case map[string]interface{}:
 					v1Map, _ := v1.(map[string]interface{})
+					newData := cloneMap(setData)
 					for k2, v2 := range v1Map {
-						setData[k2] = v2
+						newData[k2] = v2
 					}
-					vMap[k1] = setData
+					vMap[k1] = newData
 				}
 			} else {
 				vMap[k1] = v1
 			}
 		}
 		o.handleResponse(vMap)
-		o.setOpenAPIByRoute(operation, vMap)
+		if err = o.setOpenAPIByRoute(operation, vMap); err != nil {
+			if IsErrorKind(err, ErrorKindSecurity) {
+				o.g.diags.add(SeverityError, CodeSecurityNotFound, "@security", err.Error(), routesPos[k])
+				err = nil
+				continue
+			}
+			if errors.Is(err, errRefNotFound) {
+				o.g.diags.add(SeverityError, CodeRefNotFound, "ref", err.Error(), routesPos[k])
+				err = nil
+				continue
+			}
+			return
+		}
 		switch method {
 		case "get":
 			pathItem.Get = operation
@@ -257,48 +557,190 @@ func (o *openapiHandle) generateRoute(rootDir, routeDir string) {
 			pathItem.Patch = operation
 		}
 		o.t.Paths.Set(path, pathItem)
+		operations = append(operations, routeOperation{
+			key:       k,
+			pos:       routesPos[k],
+			operation: operation,
+			explicit:  operation.OperationID != "",
+			defaultId: funcMap[k].operationId(),
+			handler:   routesPos[k].String(),
+			method:    method,
+			path:      path,
+		})
 	}
+	o.setOperationIds(append(operations, o.namedOps...))
 	// 设置schemes
 	if o.t.Components == nil {
 		o.t.Components = &openapi3.Components{}
 	}
 	o.t.Components.Schemas = o.schemas
+	return
+}
+
+// 路由生成的操作，用于设置operationId
+type routeOperation struct {
+	key       string
+	pos       token.Position
+	operation *openapi3.Operation
+	explicit  bool   // 是否使用@operationId指定
+	defaultId string // 默认的operationId
+	handler   string // 路由对应的方法，多个@router共用同一个方法时相同
+	method    string
+	path      string
+}
+
+// operationId重复时报错，默认的operationId重复时提示使用@operationId指定
+func (o *openapiHandle) setOperationIds(operations []routeOperation) {
+	o.setSharedOperationIds(operations)
+	idMap := map[string]string{}
+	for _, v := range operations {
+		if !v.explicit {
+			continue
+		}
+		id := v.operation.OperationID
+		if idMap[id] != "" {
+			o.g.diags.add(SeverityError, CodeOperationId, "@operationId", fmt.Sprintf(errorOperationId, id, idMap[id]), v.pos)
+			continue
+		}
+		idMap[id] = v.key
+	}
+	for _, v := range operations {
+		if v.explicit || v.defaultId == "" {
+			continue
+		}
+		id := v.defaultId
+		if idMap[id] != "" {
+			o.g.diags.add(SeverityError, CodeOperationId, "@operationId", fmt.Sprintf(errorOperationId, id, idMap[id])+
+				"，请使用 @operationId 指定或拆分为不同的函数", v.pos)
+			continue
+		}
+		idMap[id] = v.key
+		v.operation.OperationID = id
+	}
 }
 
+// 多个@router共用同一个方法时，每个路由的operationId添加后缀。
+// 请求方式都不相同时使用请求方式，路由都不相同时使用路由，否则使用请求方式+路由，
+// 例如 /items 和 /v2/items 共用 List 时为 listItems 和 listV2Items
+func (o *openapiHandle) setSharedOperationIds(operations []routeOperation) {
+	groups := map[string][]int{}
+	var handlers []string
+	for i, v := range operations {
+		if v.handler == "" {
+			continue
+		}
+		if groups[v.handler] == nil {
+			handlers = append(handlers, v.handler)
+		}
+		groups[v.handler] = append(groups[v.handler], i)
+	}
+	for _, handler := range handlers {
+		group := groups[handler]
+		if len(group) < 2 {
+			continue
+		}
+		methods := map[string]bool{}
+		paths := map[string]bool{}
+		for _, i := range group {
+			methods[operations[i].method] = true
+			paths[operations[i].path] = true
+		}
+		for _, i := range group {
+			v := &operations[i]
+			suffix := upperFirst(v.method) + pathSuffix(v.path)
+			if len(methods) == len(group) {
+				suffix = upperFirst(v.method)
+			} else if len(paths) == len(group) {
+				suffix = pathSuffix(v.path)
+			}
+			if v.explicit {
+				v.operation.OperationID += suffix
+			} else if v.defaultId != "" {
+				v.defaultId += suffix
+			}
+		}
+	}
+}
+
+// 路由转换为operationId的后缀，例如 /v2/items/{id} 为 V2ItemsId
+func pathSuffix(path string) string {
+	var suffix string
+	for _, v := range strings.FieldsFunc(path, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		suffix += upperFirst(v)
+	}
+	return suffix
+}
+
+// 没有2XX、3XX和default返回时添加配置的返回
 func (o *openapiHandle) handleResponse(dataMap map[string]interface{}) {
+	fallback := &FallbackResponse{Status: "200"}
+	if o.g.opts.FallbackResponse != nil {
+		fallback = o.g.opts.FallbackResponse
+	}
+	if fallback.Status == "" {
+		return
+	}
 	resList, _ := dataMap["@res"].([]map[string]interface{})
-	isStatusOK := false
 	for _, resMap := range resList {
-		if resMap["status"] == "200" {
-			isStatusOK = true
+		status := toString(resMap["status"])
+		if status == "default" || strings.HasPrefix(status, "2") || strings.HasPrefix(status, "3") {
+			return
 		}
 	}
-	// 必定存在200状态
-	if !isStatusOK {
-		resList = append(resList, map[string]interface{}{
-			"in":      "text/plain",
-			"status":  "200",
-			"desc":    "Success",
-			"content": "",
-		})
-		dataMap["@res"] = resList
+	desc := fallback.Description
+	if desc == "" {
+		desc = "Success"
 	}
+	dataMap["@res"] = append(resList, map[string]interface{}{
+		"status": fallback.Status,
+		"desc":   desc,
+	})
 }
 
-func (o *openapiHandle) setOpenAPIByRoute(dist any, dataMap map[string]interface{}) {
+func (o *openapiHandle) setOpenAPIByRoute(dist any, dataMap map[string]interface{}) (err error) {
 	switch val := dist.(type) {
 	case *openapi3.Operation:
-		for k, v := range dataMap {
+		for _, k := range sortedKeys(dataMap) {
+			v := dataMap[k]
+			if strings.HasPrefix(k, extensionPrefix) {
+				val.Extensions = setExtension(val.Extensions, strings.TrimPrefix(k, "@"), v)
+				continue
+			}
 			switch k {
 			case "@summary":
 				val.Summary = toString(v)
 			case "@description":
 				val.Description = toString(v)
+			case "@operationId":
+				val.OperationID = toString(v)
+			case "@externalDocs.description", "@externalDocs.url":
+				if val.ExternalDocs == nil {
+					val.ExternalDocs = &openapi3.ExternalDocs{}
+				}
+				_, other := getIndexFirst(k, ".")
+				o.setOpenAPIByDoc(val.ExternalDocs, map[string]interface{}{
+					other: v,
+				})
+			case "@servers":
+				servers := openapi3.Servers{}
+				vList, _ := v.([]map[string]interface{})
+				for _, v1Map := range vList {
+					server := &openapi3.Server{}
+					o.setOpenAPIByDoc(server, v1Map)
+					servers = append(servers, server)
+				}
+				val.Servers = &servers
 			case "@tags":
+				// 按照注释中的顺序，重复的标签只保留第一个
 				var tags []string
 				vMap, _ := v.(map[string]interface{})
-				for k1, _ := range vMap {
-					tags = append(tags, k1)
+				sorts, _ := vMap[sortField].([]string)
+				for _, k1 := range sorts {
+					if inArray(k1, tags) == -1 {
+						tags = append(tags, k1)
+					}
 				}
 				val.Tags = tags
 			case "@param":
@@ -308,38 +750,27 @@ func (o *openapiHandle) setOpenAPIByRoute(dist any, dataMap map[string]interface
 				}
 				vList, _ := v.([]map[string]interface{})
 				for _, v1Map := range vList {
+					// 不传name时展开结构体的字段
+					if v1Map["content"] != nil && v1Map["name"] == nil && v1Map["ref"] == nil {
+						var structParams openapi3.Parameters
+						if structParams, err = o.structParams(toString(v1Map["in"]), toString(v1Map["content"])); err != nil {
+							return
+						}
+						params = append(params, structParams...)
+						continue
+					}
 					param := &openapi3.ParameterRef{}
-					o.setOpenAPIByRoute(param, v1Map)
+					if err = o.setOpenAPIByRoute(param, v1Map); err != nil {
+						return
+					}
 					params = append(params, param)
 				}
 				val.Parameters = params
 			case "@body":
 				body := &openapi3.RequestBodyRef{}
-				if val.RequestBody != nil {
-					body = val.RequestBody
-				}
-				if body.Value == nil {
-					body.Value = &openapi3.RequestBody{}
-				}
 				vMap, _ := v.(map[string]interface{})
-				ins, _ := vMap["in"].([]string)
-				if len(ins) > 0 {
-					body.Value.Content = map[string]*openapi3.MediaType{}
-					for _, in := range ins {
-						mediaType := &openapi3.MediaType{
-							Schema: &openapi3.SchemaRef{},
-						}
-						if vMap["content"] != nil {
-							o.setType(mediaType.Schema, toString(vMap["content"]), true)
-						}
-						body.Value.Content[in] = mediaType
-					}
-				}
-				for k1, v1 := range vMap {
-					switch k1 {
-					case "desc":
-						body.Value.Description = toString(v1)
-					}
+				if err = o.setOpenAPIByRoute(body, vMap); err != nil {
+					return
 				}
 				val.RequestBody = body
 			case "@res":
@@ -349,35 +780,90 @@ func (o *openapiHandle) setOpenAPIByRoute(dist any, dataMap map[string]interface
 				}
 				vList, _ := v.([]map[string]interface{})
 				for _, v1Map := range vList {
-					status := toString(v1Map["status"])
-					response := &openapi3.ResponseRef{
-						Value: &openapi3.Response{},
-					}
-					ins, _ := v1Map["in"].([]string)
-					if len(ins) > 0 {
-						response.Value.Content = map[string]*openapi3.MediaType{}
-						for _, in := range ins {
-							mediaType := &openapi3.MediaType{
-								Schema: &openapi3.SchemaRef{},
+					response := &openapi3.ResponseRef{}
+					if err = o.setOpenAPIByRoute(response, v1Map); err != nil {
+						return
+					}
+					responses.Set(toString(v1Map["status"]), response)
+				}
+				val.Responses = responses
+			case "@res.header":
+				// 在@res之后处理，status为空时添加到所有返回
+				if val.Responses == nil {
+					val.Responses = &openapi3.Responses{}
+				}
+				vList, _ := v.([]map[string]interface{})
+				for _, v1Map := range vList {
+					statusList := []string{toString(v1Map["status"])}
+					if statusList[0] == "" {
+						statusList = sortedKeys(val.Responses.Map())
+					}
+					for _, status := range statusList {
+						response := val.Responses.Value(status)
+						if response == nil {
+							response = &openapi3.ResponseRef{Value: &openapi3.Response{Description: toPtr("")}}
+							val.Responses.Set(status, response)
+						}
+						if response.Ref != "" {
+							// 不修改引用的返回，status为空时跳过，指定status时复制后添加
+							if v1Map["status"] == nil {
+								continue
 							}
-							if v1Map["content"] != nil {
-								o.setType(mediaType.Schema, toString(v1Map["content"]), true)
+							value := *response.Value
+							value.Headers = openapi3.Headers{}
+							for k2, v2 := range response.Value.Headers {
+								value.Headers[k2] = v2
 							}
-							response.Value.Content[in] = mediaType
+							response = &openapi3.ResponseRef{Value: &value}
+							val.Responses.Set(status, response)
 						}
-					}
-					for k2, v2 := range v1Map {
-						switch k2 {
-						case "desc":
-							response.Value.Description = toPtr(toString(v2))
+						if response.Value.Headers == nil {
+							response.Value.Headers = openapi3.Headers{}
+						}
+						header := &openapi3.HeaderRef{Value: &openapi3.Header{}}
+						if err = o.setOpenAPIByRoute(header, v1Map); err != nil {
+							return
 						}
+						// 引用时name为空使用引用的名称
+						name := toString(v1Map["name"])
+						if name == "" {
+							name = toString(v1Map["ref"])
+						}
+						response.Value.Headers[name] = header
 					}
-					responses.Set(status, response)
 				}
-				val.Responses = responses
+			case "@callback":
+				bodyList, _ := dataMap["@callback.body"].([]map[string]interface{})
+				resList, _ := dataMap["@callback.res"].([]map[string]interface{})
+				vList, _ := v.([]map[string]interface{})
+				for _, v1Map := range vList {
+					var operation *openapi3.Operation
+					if operation, err = o.namedOperation(v1Map, bodyList, resList); err != nil {
+						return
+					}
+					if val.Callbacks == nil {
+						val.Callbacks = openapi3.Callbacks{}
+					}
+					name := toString(v1Map["name"])
+					if val.Callbacks[name] == nil {
+						val.Callbacks[name] = &openapi3.CallbackRef{Value: openapi3.NewCallback()}
+					}
+					callback := val.Callbacks[name].Value
+					expression := toString(v1Map["expression"])
+					pathItem := callback.Value(expression)
+					if pathItem == nil {
+						pathItem = &openapi3.PathItem{}
+						callback.Set(expression, pathItem)
+					}
+					pathItem.SetOperation(namedOperationMethod(v1Map), operation)
+					o.addNamedOperation("@callback "+name, v1Map, operation)
+				}
 			case "@security":
 				vMap, _ := v.(map[string]interface{})
-				securitySchemes := o.t.Components.SecuritySchemes
+				securitySchemes := openapi3.SecuritySchemes{}
+				if o.t.Components != nil && o.t.Components.SecuritySchemes != nil {
+					securitySchemes = o.t.Components.SecuritySchemes
+				}
 				securitys := openapi3.SecurityRequirements{}
 				if val.Security != nil {
 					securitys = *val.Security
@@ -389,7 +875,7 @@ func (o *openapiHandle) setOpenAPIByRoute(dist any, dataMap map[string]interface
 						for k2, _ := range securitySchemes {
 							keys = append(keys, k2)
 						}
-						log.Fatal(fmt.Sprintf("验证"+errorNotIn, k1, strings.Join(keys, ",")))
+						return newErrorMsg(ErrorKindSecurity, "验证"+errorNotIn, k1, strings.Join(keys, ","))
 					}
 					v1 := vMap[k1]
 					security := openapi3.SecurityRequirement{}
@@ -404,11 +890,91 @@ func (o *openapiHandle) setOpenAPIByRoute(dist any, dataMap map[string]interface
 				val.Security = &securitys
 			}
 		}
+		// 废弃原因添加在描述之后，因此在描述设置之后处理
+		if vMap, ok := dataMap["@deprecated"].(map[string]interface{}); ok {
+			val.Deprecated = true
+			if reason := toString(vMap["reason"]); reason != "" {
+				if val.Description != "" {
+					val.Description += "\n\n"
+				}
+				val.Description += "Deprecated: " + reason
+			}
+			if sunset := toString(vMap["sunset"]); sunset != "" {
+				val.Extensions = setExtension(val.Extensions, "x-sunset", sunset)
+			}
+		}
+	case *openapi3.RequestBodyRef:
+		if ref := toString(dataMap["ref"]); ref != "" {
+			return o.setComponentRef(val, ref)
+		}
+		if val.Value == nil {
+			val.Value = &openapi3.RequestBody{}
+		}
+		if val.Value.Content, err = o.getContent(dataMap); err != nil {
+			return
+		}
+		for k, v := range dataMap {
+			switch k {
+			case "desc":
+				val.Value.Description = toString(v)
+			case "required":
+				val.Value.Required = v == "true"
+			}
+		}
+	case *openapi3.ResponseRef:
+		if ref := toString(dataMap["ref"]); ref != "" {
+			return o.setComponentRef(val, ref)
+		}
+		if val.Value == nil {
+			val.Value = &openapi3.Response{}
+		}
+		if val.Value.Content, err = o.getContent(dataMap); err != nil {
+			return
+		}
+		for k, v := range dataMap {
+			switch k {
+			case "desc":
+				val.Value.Description = toPtr(toString(v))
+			}
+		}
+	case *openapi3.ExampleRef:
+		if val.Value == nil {
+			val.Value = &openapi3.Example{}
+		}
+		for k, v := range dataMap {
+			switch k {
+			case "summary":
+				val.Value.Summary = toString(v)
+			case "desc":
+				val.Value.Description = toString(v)
+			case "value":
+				val.Value.Value = v
+			case "externalValue":
+				val.Value.ExternalValue = toString(v)
+			}
+		}
+	case *openapi3.HeaderRef:
+		if ref := toString(dataMap["ref"]); ref != "" {
+			return o.setComponentRef(val, ref)
+		}
+		// 和参数的处理一致，header不能有名称和位置
+		param := &openapi3.ParameterRef{Value: &val.Value.Parameter}
+		if err = o.setOpenAPIByRoute(param, dataMap); err != nil {
+			return
+		}
+		val.Value.Name, val.Value.In = "", ""
+		if val.Value.Schema == nil {
+			val.Value.Schema = &openapi3.SchemaRef{Value: &openapi3.Schema{Type: "string"}}
+		}
 	case *openapi3.ParameterRef:
+		if ref := toString(dataMap["ref"]); ref != "" {
+			return o.setComponentRef(val, ref)
+		}
 		if val.Value == nil {
 			val.Value = &openapi3.Parameter{}
 		}
-		for k, v := range dataMap {
+		for _, k := range sortedKeys(dataMap) {
+			v := dataMap[k]
 			switch k {
 			case "in":
 				val.Value.In = toString(v)
@@ -420,17 +986,41 @@ func (o *openapiHandle) setOpenAPIByRoute(dist any, dataMap map[string]interface
 						Value: &openapi3.Schema{},
 					}
 				}
+				schema := val.Value.Schema.Value
 				valType := toString(v)
-				val.Value.Schema.Value.Type = o.getType(valType)
-				if val.Value.Schema.Value.Type != valType {
-					val.Value.Schema.Value.Format = valType
+				if itemType, ok := arrayItemType(valType); ok {
+					schema.Type = openapi3.TypeArray
+					if schema.Items == nil {
+						schema.Items = &openapi3.SchemaRef{Value: &openapi3.Schema{}}
+					}
+					schema, valType = schema.Items.Value, itemType
+				}
+				if valType == openapi3.TypeObject {
+					schema.Type = valType
+				} else if schema.Type = o.getType(valType); schema.Type != valType {
+					schema.Format = valType
 				}
+			case "style":
+				val.Value.Style = toString(v)
+			case "explode":
+				val.Value.Explode = toPtr(v == "true")
+			case "allowEmptyValue":
+				val.Value.AllowEmptyValue = v == "true"
+			case "allowReserved":
+				val.Value.AllowReserved = v == "true"
 			case "required":
 				if v == "true" {
 					val.Value.Required = true
 				}
 			case "desc":
 				val.Value.Description = toString(v)
+			case "deprecated":
+				val.Value.Deprecated = v == "true"
+			case "examples":
+				vList, _ := v.([]string)
+				if val.Value.Examples, err = o.getExamples(vList); err != nil {
+					return
+				}
 			case "minimum":
 				if val.Value.Schema == nil {
 					val.Value.Schema = &openapi3.SchemaRef{
@@ -465,7 +1055,7 @@ func (o *openapiHandle) setOpenAPIByRoute(dist any, dataMap map[string]interface
 						Value: &openapi3.Schema{},
 					}
 				}
-				val.Value.Schema.Value.Example = v
+				val.Value.Schema.Value.Example = o.getTypeValue(toString(dataMap["type"]), toString(v))
 			case "default":
 				if val.Value.Schema == nil {
 					val.Value.Schema = &openapi3.SchemaRef{
@@ -480,10 +1070,263 @@ func (o *openapiHandle) setOpenAPIByRoute(dist any, dataMap map[string]interface
 					}
 				}
 				vList, _ := v.([]string)
-				val.Value.Schema.Value.Enum = toSliceInterface(vList)
+				schema := val.Value.Schema.Value
+				// 数组的枚举为元素的枚举
+				if itemType, ok := arrayItemType(toString(dataMap["type"])); ok {
+					if schema.Items == nil {
+						schema.Items = &openapi3.SchemaRef{Value: &openapi3.Schema{}}
+					}
+					schema = schema.Items.Value
+					enums := make([]interface{}, 0, len(vList))
+					for _, v1 := range vList {
+						enum := o.getTypeValue(itemType, v1)
+						// 验证示例时枚举中的整数按float64比较
+						if n, ok := enum.(int64); ok {
+							enum = float64(n)
+						}
+						enums = append(enums, enum)
+					}
+					schema.Enum = enums
+					continue
+				}
+				schema.Enum = toSliceInterface(vList)
 			}
 		}
+		// 对象参数使用结构体，例如 style=deepObject
+		if content := toString(dataMap["content"]); content != "" {
+			val.Value.Schema = &openapi3.SchemaRef{}
+			o.setType(val.Value.Schema, content, false)
+		}
 	}
+	return
+}
+
+// 回调和webhook的操作，请求和返回使用name关联，和路由的@body、@res一致
+func (o *openapiHandle) namedOperation(opMap map[string]interface{}, bodyList, resList []map[string]interface{}) (
+	operation *openapi3.Operation, err error) {
+	name := toString(opMap["name"])
+	dataMap := map[string]interface{}{}
+	for k, k1 := range map[string]string{"summary": "@summary", "desc": "@description", "operationId": "@operationId"} {
+		if opMap[k] != nil {
+			dataMap[k1] = opMap[k]
+		}
+	}
+	for _, vMap := range bodyList {
+		if toString(vMap["name"]) == name {
+			dataMap["@body"] = vMap
+		}
+	}
+	var list []map[string]interface{}
+	for _, vMap := range resList {
+		if toString(vMap["name"]) == name {
+			list = append(list, vMap)
+		}
+	}
+	if len(list) > 0 {
+		dataMap["@res"] = list
+	}
+	o.handleResponse(dataMap)
+	operation = &openapi3.Operation{}
+	err = o.setOpenAPIByRoute(operation, dataMap)
+	return
+}
+
+// 记录指定了operationId的回调和webhook操作
+func (o *openapiHandle) addNamedOperation(key string, opMap map[string]interface{}, operation *openapi3.Operation) {
+	if operation.OperationID == "" {
+		return
+	}
+	o.namedOps = append(o.namedOps, routeOperation{
+		key:       key,
+		pos:       annotationPos(opMap),
+		operation: operation,
+		explicit:  true,
+	})
+}
+
+// 回调和webhook的请求方式，默认为post
+func namedOperationMethod(opMap map[string]interface{}) string {
+	if method := toString(opMap["method"]); method != "" {
+		return strings.ToUpper(method)
+	}
+	return http.MethodPost
+}
+
+// 参数位置对应的结构体标签，按顺序使用第一个存在的标签作为参数名称
+var paramInTags = map[string][]string{
+	openapi3.ParameterInQuery:  {"query", "form"},
+	openapi3.ParameterInPath:   {"uri", "path"},
+	openapi3.ParameterInHeader: {"header"},
+	openapi3.ParameterInCookie: {"cookie"},
+}
+
+// 将结构体的字段展开为参数，存在其他位置标签的字段跳过，没有位置标签时使用字段名称
+func (o *openapiHandle) structParams(in, content string) (params openapi3.Parameters, err error) {
+	o.instantiate(content)
+	name := content
+	if o.sameStructs[name] != "" {
+		name = o.sameStructs[name]
+	}
+	strInfo := o.structs[name]
+	if strInfo == nil {
+		return nil, newRefError("参数的结构体 %v 不存在", content)
+	}
+	for _, field := range strInfo.list {
+		paramName, ok := o.fieldParamName(in, field)
+		if !ok {
+			continue
+		}
+		schemaRef, required := o.fieldSchema(field, map[string]int{})
+		if binding := field.extends["binding"]; inArray("required", binding) != -1 {
+			required = true
+		}
+		param := &openapi3.Parameter{
+			In:          in,
+			Name:        paramName,
+			Description: field.comment,
+			Required:    required || in == openapi3.ParameterInPath,
+			Deprecated:  schemaRef.Value.Deprecated,
+			Schema:      schemaRef,
+		}
+		// 描述和废弃标记在参数中设置
+		if schemaRef.Ref == "" {
+			schemaRef.Value.Description, schemaRef.Value.Deprecated = "", false
+		}
+		params = append(params, &openapi3.ParameterRef{Value: param})
+	}
+	return
+}
+
+// 字段在参数位置中的名称，标签值为 - 时返回false
+// 没有位置标签的字段只作为query参数，和gin的form绑定一致使用字段名称
+func (o *openapiHandle) fieldParamName(in string, field structField) (name string, ok bool) {
+	for _, tag := range paramInTags[in] {
+		if list := field.extends[tag]; len(list) > 0 {
+			return list[0], list[0] != "-"
+		}
+	}
+	if in != openapi3.ParameterInQuery {
+		return
+	}
+	for _, tags := range paramInTags {
+		for _, tag := range tags {
+			if field.extends[tag] != nil {
+				return
+			}
+		}
+	}
+	return field.fieldName, true
+}
+
+// 请求和返回的内容，每个类型使用相同的结构体和示例
+func (o *openapiHandle) getContent(dataMap map[string]interface{}) (content openapi3.Content, err error) {
+	ins, _ := dataMap["in"].([]string)
+	if len(ins) == 0 {
+		return
+	}
+	examplesList, _ := dataMap["examples"].([]string)
+	examples, err := o.getExamples(examplesList)
+	if err != nil {
+		return
+	}
+	content = openapi3.Content{}
+	for _, in := range ins {
+		mediaType := &openapi3.MediaType{
+			Schema:   o.mediaTypeSchema(in),
+			Examples: examples,
+		}
+		if dataMap["content"] != nil {
+			mediaType.Schema = &openapi3.SchemaRef{}
+			o.setType(mediaType.Schema, toString(dataMap["content"]), true)
+		}
+		content[in] = mediaType
+	}
+	return
+}
+
+// 二进制的媒体类型，image、audio、video、font 类型都是二进制
+var binaryMediaTypes = []string{
+	"application/octet-stream",
+	"application/pdf",
+	"application/zip",
+	"application/gzip",
+	"application/x-tar",
+	"application/x-7z-compressed",
+	"application/msword",
+	"application/vnd.ms-excel",
+	"application/vnd.ms-powerpoint",
+}
+
+// 不传content时媒体类型默认的schema，二进制类型为 string(binary)，文本类型为 string
+func (o *openapiHandle) mediaTypeSchema(mediaType string) *openapi3.SchemaRef {
+	mediaType = strings.ToLower(mediaType)
+	typ, subtype, _ := strings.Cut(mediaType, "/")
+	switch {
+	case inArray(typ, []string{"image", "audio", "video", "font"}) != -1, inArray(mediaType, binaryMediaTypes) != -1,
+		strings.HasPrefix(subtype, "vnd.openxmlformats-officedocument."):
+		return &openapi3.SchemaRef{Value: &openapi3.Schema{Type: openapi3.TypeString, Format: "binary"}}
+	case typ == "text", mediaType == "application/x-ndjson":
+		return &openapi3.SchemaRef{Value: &openapi3.Schema{Type: openapi3.TypeString}}
+	}
+	return &openapi3.SchemaRef{}
+}
+
+// 引用 @components.examples 中定义的示例
+func (o *openapiHandle) getExamples(names []string) (examples openapi3.Examples, err error) {
+	for _, name := range names {
+		example := &openapi3.ExampleRef{}
+		if err = o.setComponentRef(example, name); err != nil {
+			return
+		}
+		if examples == nil {
+			examples = openapi3.Examples{}
+		}
+		examples[name] = example
+	}
+	return
+}
+
+// 引用components中定义的组件，输出$ref，Value为组件的值用于验证
+func (o *openapiHandle) setComponentRef(dist any, name string) (err error) {
+	components := o.t.Components
+	if components == nil {
+		components = &openapi3.Components{}
+	}
+	var title string
+	var keys []string
+	switch val := dist.(type) {
+	case *openapi3.ParameterRef:
+		if v := components.Parameters[name]; v != nil {
+			val.Ref, val.Value = "#/components/parameters/"+name, v.Value
+			return
+		}
+		title, keys = "参数", sortedKeys(components.Parameters)
+	case *openapi3.RequestBodyRef:
+		if v := components.RequestBodies[name]; v != nil {
+			val.Ref, val.Value = "#/components/requestBodies/"+name, v.Value
+			return
+		}
+		title, keys = "请求", sortedKeys(components.RequestBodies)
+	case *openapi3.ResponseRef:
+		if v := components.Responses[name]; v != nil {
+			val.Ref, val.Value = "#/components/responses/"+name, v.Value
+			return
+		}
+		title, keys = "返回", sortedKeys(components.Responses)
+	case *openapi3.HeaderRef:
+		if v := components.Headers[name]; v != nil {
+			val.Ref, val.Value = "#/components/headers/"+name, v.Value
+			return
+		}
+		title, keys = "返回头", sortedKeys(components.Headers)
+	case *openapi3.ExampleRef:
+		if v := components.Examples[name]; v != nil {
+			val.Ref, val.Value = "#/components/examples/"+name, v.Value
+			return
+		}
+		title, keys = "示例", sortedKeys(components.Examples)
+	}
+	return newRefError("引用的"+title+errorNotIn, name, strings.Join(keys, ","))
 }
 
 func (o *openapiHandle) setType(schemeRef *openapi3.SchemaRef, types string, isContent bool, alreadyMaps ...map[string]int) {
@@ -497,6 +1340,7 @@ func (o *openapiHandle) setType(schemeRef *openapi3.SchemaRef, types string, isC
 	if schemeRef.Value == nil {
 		schemeRef.Value = &openapi3.Schema{}
 	}
+	o.instantiate(types)
 	if alreadyMap[types] > 0 {
 		// 第一次重复需要设置ref值
 		if alreadyMap[types] == 1 {
@@ -507,6 +1351,11 @@ func (o *openapiHandle) setType(schemeRef *openapi3.SchemaRef, types string, isC
 		}
 		return
 	}
+	// 类型映射优先于结构体，例如 time.Time
+	if mapping, ok := o.g.typeMappings[types]; ok {
+		mapping.setSchema(schemeRef.Value)
+		return
+	}
 	if o.sameStructs[types] != "" {
 		o.setType(schemeRef, o.sameStructs[types], false, alreadyMap)
 		return
@@ -521,17 +1370,26 @@ func (o *openapiHandle) setType(schemeRef *openapi3.SchemaRef, types string, isC
 		o.setType(schemeRef.Value.Items, types, false, alreadyMap)
 		return
 	}
-	// 判断是否是对象
+	// 判断是否是对象，值使用additionalProperties，key不是字符串时使用x-key-type说明
 	tempTypes = strings.TrimPrefix(types, "map[")
 	if tempTypes != types {
-		mapTypes := ""
-		mapTypes, types = getIndexFirst(tempTypes, "]")
-		mapTypes = strings.ReplaceAll(mapTypes, "/", ".")
+		keyTypes := ""
+		keyTypes, types = getIndexFirst(tempTypes, "]")
 		schemeRef.Value.Type = "object"
-		schemeRef.Value.Properties = map[string]*openapi3.SchemaRef{
-			mapTypes: {},
+		if keyType := o.getType(keyTypes); keyType != "string" {
+			schemeRef.Value.Extensions = setExtension(schemeRef.Value.Extensions, mapKeyTypeExtension, keyType)
 		}
-		o.setType(schemeRef.Value.Properties[mapTypes], types, false, alreadyMap)
+		if types == anyType {
+			// 值为任意类型时是自由对象
+			schemeRef.Value.AdditionalProperties = openapi3.AdditionalProperties{Has: toPtr(true)}
+			return
+		}
+		schemeRef.Value.AdditionalProperties = openapi3.AdditionalProperties{Schema: &openapi3.SchemaRef{}}
+		o.setType(schemeRef.Value.AdditionalProperties.Schema, types, false, alreadyMap)
+		return
+	}
+	// 任意类型不限制schema
+	if types == anyType {
 		return
 	}
 	strInfo := o.structs[types]
@@ -570,56 +1428,132 @@ func (o *openapiHandle) setScheme(strInfo *structInfo, alreadyMaps ...map[string
 	}
 	var requiredList []string
 	for _, v2 := range strInfo.list {
-		fieldName := v2.fieldName
-		fieldSchemaRef := &openapi3.SchemaRef{
-			Value: &openapi3.Schema{
-				Description: v2.comment,
-			},
+		fieldSchemaRef, required := o.fieldSchema(v2, alreadyMap)
+		if v2.extends["required"] == nil {
+			required = o.defaultRequired(v2)
 		}
-		o.setType(fieldSchemaRef, v2.fieldType, false, alreadyMap)
-		for k3, v3 := range v2.extends {
-			switch k3 {
-			case "minimum":
-				// 数字验证，最小值
-				fieldSchemaRef.Value.Min = toPtr(toFloat64(v3[0]))
-			case "maximum":
-				// 数字验证，最大值
-				fieldSchemaRef.Value.Max = toPtr(toFloat64(v3[0]))
-			case "minLength":
-				// 字符串验证，最小长度
-				fieldSchemaRef.Value.MinLength = toUint64(v3[0])
-			case "maxLength":
-				// 字符串验证，最大长度
-				fieldSchemaRef.Value.MaxLength = toPtr(toUint64(v3[0]))
-			case "minItems":
-				// 数组验证，最小长度
-				fieldSchemaRef.Value.MinItems = toUint64(v3[0])
-			case "maxItems":
-				// 数组验证，最大长度
-				fieldSchemaRef.Value.MaxItems = toPtr(toUint64(v3[0]))
-			case "example":
-				// 实例
-				fieldSchemaRef.Value.Example = v3[0]
-			case "default":
-				// 默认值
-				fieldSchemaRef.Value.Default = o.getTypeValue(v2.fieldType, v3[0])
-			case "enum":
-				// 限定值
-				fieldSchemaRef.Value.Enum = toSliceInterface(v3)
-			case "required":
-				if v3[0] == "true" {
-					requiredList = append(requiredList, fieldName)
-				}
-			}
+		if required {
+			requiredList = append(requiredList, v2.fieldName)
 		}
-		schemaRef.Value.Properties[fieldName] = fieldSchemaRef
+		schemaRef.Value.Properties[v2.fieldName] = fieldSchemaRef
 	}
 	schemaRef.Value.Required = requiredList
 	o.schemas[strInfo.name] = schemaRef
 	return
 }
 
+// json标签的string选项生效的类型
+var jsonStringTypes = []string{openapi3.TypeInteger, openapi3.TypeNumber, openapi3.TypeBoolean}
+
+// 类型映射的值不符合映射的格式时不输出并警告，例如 time.Time 的值不是RFC3339格式，避免文档验证失败
+func (o *openapiHandle) mappedValue(field structField, key string, schema *openapi3.Schema, value interface{}) interface{} {
+	if _, ok := o.g.typeMappings[field.fieldType]; !ok || schema.Format == "" {
+		return value
+	}
+	if err := (&openapi3.Schema{Type: schema.Type, Format: schema.Format}).VisitJSON(value); err == nil {
+		return value
+	}
+	warnKey := field.pos.String() + key
+	if !o.valueWarnings[warnKey] {
+		o.valueWarnings[warnKey] = true
+		o.g.diags.add(SeverityWarning, CodeFormatValue, key, fmt.Sprintf(errorFormatValue, value, field.fieldType, schema.Format), field.pos)
+	}
+	return nil
+}
+
+// 字段没有 required 标签时是否必填
+func (o *openapiHandle) defaultRequired(field structField) bool {
+	return o.g.opts.RequiredPolicy == RequiredPolicyOmitempty && !field.omitEmpty && !field.pointer
+}
+
+// 结构体字段的schema，标签中的验证字段设置在schema中
+func (o *openapiHandle) fieldSchema(field structField, alreadyMap map[string]int) (fieldSchemaRef *openapi3.SchemaRef, required bool) {
+	fieldSchemaRef = &openapi3.SchemaRef{
+		Value: &openapi3.Schema{
+			Description: field.comment,
+		},
+	}
+	o.setType(fieldSchemaRef, field.fieldType, false, alreadyMap)
+	if warnKey := field.pos.String() + "inline"; field.inline && !o.valueWarnings[warnKey] {
+		o.valueWarnings[warnKey] = true
+		o.g.diags.add(SeverityWarning, CodeInline, "json", fmt.Sprintf(errorInline, field.fieldName, field.fieldType), field.pos)
+	}
+	// json标签的string选项，数字和布尔值使用字符串，原类型作为format
+	if field.jsonString && fieldSchemaRef.Ref == "" && inArray(fieldSchemaRef.Value.Type, jsonStringTypes) != -1 {
+		if fieldSchemaRef.Value.Format == "" {
+			fieldSchemaRef.Value.Format = fieldSchemaRef.Value.Type
+		}
+		fieldSchemaRef.Value.Type = openapi3.TypeString
+	}
+	// example和default按照schema的类型转换，只有integer、number和boolean转换，其他类型保持字符串
+	valueType := fieldSchemaRef.Value.Type
+	if fieldSchemaRef.Ref != "" {
+		valueType = openapi3.TypeString
+	}
+	for _, k := range sortedKeys(field.extends) {
+		v := field.extends[k]
+		switch k {
+		case "minimum":
+			// 数字验证，最小值
+			fieldSchemaRef.Value.Min = toPtr(toFloat64(v[0]))
+		case "maximum":
+			// 数字验证，最大值
+			fieldSchemaRef.Value.Max = toPtr(toFloat64(v[0]))
+		case "minLength":
+			// 字符串验证，最小长度
+			fieldSchemaRef.Value.MinLength = toUint64(v[0])
+		case "maxLength":
+			// 字符串验证，最大长度
+			fieldSchemaRef.Value.MaxLength = toPtr(toUint64(v[0]))
+		case "minItems":
+			// 数组验证，最小长度
+			fieldSchemaRef.Value.MinItems = toUint64(v[0])
+		case "maxItems":
+			// 数组验证，最大长度
+			fieldSchemaRef.Value.MaxItems = toPtr(toUint64(v[0]))
+		case "example":
+			// 实例
+			fieldSchemaRef.Value.Example = o.mappedValue(field, k, fieldSchemaRef.Value, o.getTypeValue(valueType, v[0]))
+		case "default":
+			// 默认值
+			fieldSchemaRef.Value.Default = o.mappedValue(field, k, fieldSchemaRef.Value, o.getTypeValue(valueType, v[0]))
+		case "enum":
+			// 限定值
+			fieldSchemaRef.Value.Enum = toSliceInterface(v)
+		case "required":
+			required = v[0] == "true"
+		case "deprecated":
+			// 废弃字段
+			fieldSchemaRef.Value.Deprecated = v[0] == "true"
+		}
+	}
+	// 指针字段可以为null，标签 nullable 优先，不限制类型时本身包括null
+	nullable := fieldSchemaRef.Value.Nullable || field.pointer && !o.g.opts.PointerNotNullable
+	if v := field.extends["nullable"]; len(v) > 0 {
+		nullable = v[0] == "true"
+	}
+	fieldSchemaRef.Value.Nullable = nullable && (fieldSchemaRef.Ref != "" || fieldSchemaRef.Value.Type != "")
+	if fieldSchemaRef.Ref != "" && (fieldSchemaRef.Value.Deprecated || fieldSchemaRef.Value.Nullable) {
+		// 3.0版本$ref同级字段无效，使用allOf保留废弃和nullable标记
+		fieldSchemaRef = &openapi3.SchemaRef{Value: &openapi3.Schema{
+			AllOf:       openapi3.SchemaRefs{{Ref: fieldSchemaRef.Ref, Value: &openapi3.Schema{}}},
+			Description: fieldSchemaRef.Value.Description,
+			Deprecated:  fieldSchemaRef.Value.Deprecated,
+			Nullable:    fieldSchemaRef.Value.Nullable,
+		}}
+	}
+	return
+}
+
 func (o *openapiHandle) getTypeValue(types string, value string) (rs interface{}) {
+	// 数组的值用,分割
+	if itemType, ok := arrayItemType(types); ok {
+		var list []interface{}
+		for _, v := range strings.Split(value, thirdListCutSign) {
+			list = append(list, o.getTypeValue(itemType, strings.TrimSpace(v)))
+		}
+		return list
+	}
 	rs = value
 	types = o.getType(types)
 	switch types {
@@ -633,9 +1567,20 @@ func (o *openapiHandle) getTypeValue(types string, value string) (rs interface{}
 	return rs
 }
 
+// 参数类型 array(元素类型) 中的元素类型，例如 array(integer)
+func arrayItemType(types string) (itemType string, ok bool) {
+	if strings.HasPrefix(types, "array(") && strings.HasSuffix(types, ")") {
+		return types[len("array(") : len(types)-1], true
+	}
+	return types, false
+}
+
 func (o *openapiHandle) getType(s string) string {
+	if mapping, ok := o.g.typeMappings[s]; ok && mapping.Type != "" {
+		return mapping.Type
+	}
 	switch s {
-	case "int", "int8", "int16", "int32", "int64", "uint", "uint8", "uint16", "uint32", "uint64":
+	case "int", "int8", "int16", "int32", "int64", "uint", "uint8", "uint16", "uint32", "uint64", "byte", "rune":
 		return "integer"
 	case "float32", "float64":
 		return "number"
@@ -647,22 +1592,150 @@ func (o *openapiHandle) getType(s string) string {
 	return "string"
 }
 
-func (o *openapiHandle) generateDoc(docPath string) {
-	asts := new(astHandle)
-	err := asts.load(docPath, projectModName, astLoadTypeDoc)
+func (o *openapiHandle) generateDoc(docPath string) (err error) {
+	asts := o.g.newAst()
+	err = asts.load(docPath, o.g.projectModName, astLoadTypeDoc)
 	if err != nil {
-		log.Fatal(err)
+		return
 	}
 	o.setOpenAPIByDoc(o.t, asts.docs)
 	// 处理通用路由
 	o.globalRoutes["@res"] = asts.docs["@global.res"]
 	o.globalRoutes["@param"] = asts.docs["@global.param"]
+	o.globalRoutes["@res.header"] = asts.docs["@global.res.header"]
+	o.addImportStruct(o.globalRoutes)
+	// webhook在结构体解析后生成
+	if asts.docs["@webhooks"] != nil && o.only31("@webhooks", "webhooks") != "" {
+		for _, k := range []string{"@webhooks", "@webhooks.body", "@webhooks.res"} {
+			o.webhookDocs[k] = asts.docs[k]
+		}
+		o.addImportStruct(asts.docs)
+	}
+	// 可引用的组件在结构体解析后生成
+	for _, k := range componentKeys {
+		if v := asts.docs["@components."+k]; v != nil {
+			o.componentDocs[k] = v
+			vList, _ := v.([]map[string]interface{})
+			for _, vMap := range vList {
+				if content := toString(vMap["content"]); content != "" {
+					o.importStructs[strings.TrimPrefix(content, "[]")] = true
+				}
+			}
+		}
+	}
+	return
+}
+
+// 生成 @webhooks 中定义的webhook，只支持3.1版本
+func (o *openapiHandle) setWebhooks() (err error) {
+	vList, _ := o.webhookDocs["@webhooks"].([]map[string]interface{})
+	bodyList, _ := o.webhookDocs["@webhooks.body"].([]map[string]interface{})
+	resList, _ := o.webhookDocs["@webhooks.res"].([]map[string]interface{})
+	for _, vMap := range vList {
+		var operation *openapi3.Operation
+		if operation, err = o.namedOperation(vMap, bodyList, resList); err != nil {
+			if errors.Is(err, errRefNotFound) {
+				o.g.diags.add(SeverityError, CodeRefNotFound, "@webhooks", err.Error(), annotationPos(vMap))
+				err = nil
+				continue
+			}
+			return
+		}
+		if o.webhooks == nil {
+			o.webhooks = map[string]*openapi3.PathItem{}
+		}
+		name := toString(vMap["name"])
+		if o.webhooks[name] == nil {
+			o.webhooks[name] = &openapi3.PathItem{}
+		}
+		o.webhooks[name].SetOperation(namedOperationMethod(vMap), operation)
+		o.addNamedOperation("@webhooks "+name, vMap, operation)
+	}
+	return
+}
+
+// 可引用的组件，按照顺序生成，示例和返回头需要在被引用前生成
+var componentKeys = []string{"examples", "headers", "parameters", "requestBodies", "responses"}
+
+// 生成 @components 中定义的可引用组件
+func (o *openapiHandle) setComponents() (err error) {
+	if o.t.Components == nil {
+		o.t.Components = &openapi3.Components{}
+	}
+	components := o.t.Components
+	for _, k := range componentKeys {
+		vList, _ := o.componentDocs[k].([]map[string]interface{})
+		for _, vMap := range vList {
+			field := toString(vMap["field"])
+			switch k {
+			case "examples":
+				example := &openapi3.ExampleRef{}
+				if err = o.setOpenAPIByRoute(example, vMap); err == nil {
+					if components.Examples == nil {
+						components.Examples = openapi3.Examples{}
+					}
+					components.Examples[field] = example
+				}
+			case "headers":
+				header := &openapi3.HeaderRef{Value: &openapi3.Header{}}
+				if err = o.setOpenAPIByRoute(header, vMap); err == nil {
+					if components.Headers == nil {
+						components.Headers = openapi3.Headers{}
+					}
+					components.Headers[field] = header
+				}
+			case "parameters":
+				param := &openapi3.ParameterRef{}
+				if err = o.setOpenAPIByRoute(param, vMap); err == nil {
+					if components.Parameters == nil {
+						components.Parameters = openapi3.ParametersMap{}
+					}
+					components.Parameters[field] = param
+				}
+			case "requestBodies":
+				body := &openapi3.RequestBodyRef{}
+				if err = o.setOpenAPIByRoute(body, vMap); err == nil {
+					if components.RequestBodies == nil {
+						components.RequestBodies = openapi3.RequestBodies{}
+					}
+					components.RequestBodies[field] = body
+				}
+			case "responses":
+				response := &openapi3.ResponseRef{}
+				if err = o.setOpenAPIByRoute(response, vMap); err == nil {
+					if components.Responses == nil {
+						components.Responses = openapi3.ResponseBodies{}
+					}
+					components.Responses[field] = response
+				}
+			}
+			if errors.Is(err, errRefNotFound) {
+				o.g.diags.add(SeverityError, CodeRefNotFound, "@components."+k, err.Error(), annotationPos(vMap))
+				err = nil
+			}
+			if err != nil {
+				return
+			}
+		}
+	}
+	return
+}
+
+// 注释所在的位置，需要在验证中设置 isPos
+func annotationPos(vMap map[string]interface{}) token.Position {
+	pos, _ := vMap[posField].(token.Position)
+	return pos
 }
 
 func (o *openapiHandle) setOpenAPIByDoc(dist any, dataMap map[string]interface{}) {
 	switch val := dist.(type) {
 	case *openapi3.T:
-		for k, v := range dataMap {
+		for _, k := range sortedKeys(dataMap) {
+			v := dataMap[k]
+			if strings.HasPrefix(k, extensionPrefix) {
+				val.Extensions = setExtension(val.Extensions, strings.TrimPrefix(k, "@"), v)
+				continue
+			}
 			title, other := getIndexFirst(k, ".")
 			switch title {
 			case "@info":
@@ -755,11 +1828,14 @@ func (o *openapiHandle) setOpenAPIByDoc(dist any, dataMap map[string]interface{}
 			}
 		}
 	case *openapi3.Info:
-		for k, v := range dataMap {
+		for _, k := range sortedKeys(dataMap) {
+			v := dataMap[k]
 			title, other := getIndexFirst(k, ".")
 			switch title {
 			case "title":
 				val.Title = toString(v)
+			case "summary":
+				o.infoSummary = o.only31("@info.summary", toString(v))
 			case "description":
 				val.Description = toString(v)
 			case "termsOfService":
@@ -787,7 +1863,8 @@ func (o *openapiHandle) setOpenAPIByDoc(dist any, dataMap map[string]interface{}
 			}
 		}
 	case *openapi3.ExternalDocs:
-		for k, v := range dataMap {
+		for _, k := range sortedKeys(dataMap) {
+			v := dataMap[k]
 			switch k {
 			case "url":
 				val.URL = toString(v)
@@ -796,7 +1873,8 @@ func (o *openapiHandle) setOpenAPIByDoc(dist any, dataMap map[string]interface{}
 			}
 		}
 	case *openapi3.Contact:
-		for k, v := range dataMap {
+		for _, k := range sortedKeys(dataMap) {
+			v := dataMap[k]
 			switch k {
 			case "name":
 				val.Name = toString(v)
@@ -807,16 +1885,20 @@ func (o *openapiHandle) setOpenAPIByDoc(dist any, dataMap map[string]interface{}
 			}
 		}
 	case *openapi3.License:
-		for k, v := range dataMap {
+		for _, k := range sortedKeys(dataMap) {
+			v := dataMap[k]
 			switch k {
 			case "name":
 				val.Name = toString(v)
 			case "url":
 				val.URL = toString(v)
+			case "identifier":
+				o.licenseIdentifier = o.only31("@info.license.identifier", toString(v))
 			}
 		}
 	case *openapi3.Server:
-		for k, v := range dataMap {
+		for _, k := range sortedKeys(dataMap) {
+			v := dataMap[k]
 			switch k {
 			case "url":
 				val.URL = toString(v)
@@ -825,7 +1907,8 @@ func (o *openapiHandle) setOpenAPIByDoc(dist any, dataMap map[string]interface{}
 			}
 		}
 	case *openapi3.Tag:
-		for k, v := range dataMap {
+		for _, k := range sortedKeys(dataMap) {
+			v := dataMap[k]
 			switch k {
 			case "name":
 				val.Name = toString(v)
@@ -835,3 +1918,13 @@ func (o *openapiHandle) setOpenAPIByDoc(dist any, dataMap map[string]interface{}
 		}
 	}
 }
+
+// 3.1版本才支持的注释，其他版本忽略并警告
+func (o *openapiHandle) only31(key string, value string) string {
+	if o.g.version == OpenAPIVersion31 {
+		return value
+	}
+	o.g.diags.add(SeverityWarning, CodeVersion, key, fmt.Sprintf(errorOnly31, o.g.version),
+		token.Position{Filename: o.docPath})
+	return ""
+}
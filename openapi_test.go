@@ -1,7 +1,1348 @@
 package openapi
 
-import "testing"
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/getkin/kin-openapi/openapi3"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"testing/fstest"
+)
 
 func TestGenerateOpenAPI(t *testing.T) {
 	GenerateOpenAPI("./", "./examples", "./examples/doc.go", "./examples/docs", "")
 }
+
+func TestGenerate(t *testing.T) {
+	doc, err := Generate(context.Background(), Options{
+		RootDir:  "./",
+		RouteDir: "./examples",
+		DocPath:  "./examples/doc.go",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if doc.Paths.Value("/user/list") == nil {
+		t.Fatal("路由 /user/list 未生成")
+	}
+	_, err = Generate(context.Background(), Options{
+		RootDir:  "./examples",
+		RouteDir: "./examples",
+		DocPath:  "./examples/doc.go",
+	})
+	if !IsErrorKind(err, ErrorKindMod) {
+		t.Fatalf("错误类型应该是 %v，实际是 %v", ErrorKindMod, err)
+	}
+}
+
+func TestGenerateDiagnostics(t *testing.T) {
+	_, err := Generate(context.Background(), Options{
+		RootDir:  "./",
+		RouteDir: "./testdata/diagnostics",
+		DocPath:  "./examples/doc.go",
+	})
+	var e *Error
+	if !errors.As(err, &e) {
+		t.Fatalf("应该返回 *Error，实际是 %v", err)
+	}
+	codes := []string{CodeNotIn, CodeNotStatus, CodeNotBool, CodeNotIn}
+	if len(e.Diagnostics) != len(codes) {
+		t.Fatalf("应该有 %v 个诊断，实际是 %v", len(codes), e.Diagnostics)
+	}
+	for i, v := range e.Diagnostics {
+		if v.Code != codes[i] || v.Severity != SeverityError || v.Pos.Line == 0 {
+			t.Fatalf("第 %v 个诊断错误：%v", i, v)
+		}
+	}
+}
+
+func TestGenerateConcurrent(t *testing.T) {
+	projects := []string{"./testdata/concurrent/user", "./testdata/concurrent/order"}
+	generateJson := func(dir string) string {
+		doc, err := Generate(context.Background(), Options{
+			RootDir:  dir,
+			RouteDir: dir,
+			DocPath:  filepath.Join(dir, "doc.go"),
+		})
+		if err != nil {
+			t.Error(err)
+			return ""
+		}
+		buf, err := json.Marshal(doc)
+		if err != nil {
+			t.Error(err)
+		}
+		return string(buf)
+	}
+	// 顺序执行的结果
+	want := map[string]string{}
+	for _, dir := range projects {
+		want[dir] = generateJson(dir)
+	}
+	// 并发执行的结果必须和顺序执行一致
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		for _, dir := range projects {
+			wg.Add(1)
+			go func(dir string) {
+				defer wg.Done()
+				if got := generateJson(dir); got != want[dir] {
+					t.Errorf("%v 并发生成结果不一致\n%v\n%v", dir, got, want[dir])
+				}
+			}(dir)
+		}
+	}
+	wg.Wait()
+}
+
+func TestGenerateFS(t *testing.T) {
+	doc, err := Generate(context.Background(), Options{
+		RootDir:  ".",
+		RouteDir: ".",
+		DocPath:  "doc.go",
+		FS:       os.DirFS("./testdata/concurrent/user"),
+		Overlay: map[string][]byte{
+			// 未保存的新文件
+			"routes/delete.go": []byte(`package routes
+
+// DeleteUser
+// @summary: 删除user
+// @param: in=path; name=id; type=integer; required
+// @res: status=200; in=application/json; content=models.User; desc=成功
+// @router: method=delete;path=/user/{id}
+func DeleteUser() {
+}
+`),
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if doc.Paths.Value("/user/{id}") == nil || doc.Paths.Value("/user/{id}").Delete == nil {
+		t.Fatal("覆盖文件中的路由未生成")
+	}
+	if doc.Components.Schemas["example.com.user.models.User"] == nil {
+		t.Fatal("fs.FS 中的结构体未生成")
+	}
+}
+
+func TestEmit(t *testing.T) {
+	RegisterFormat("title", ".txt", func(t *openapi3.T) ([]byte, error) {
+		return []byte(t.Info.Title), nil
+	})
+	buf := &bytes.Buffer{}
+	outDir := t.TempDir()
+	yamlEmitter, err := NewFileEmitter(FormatYAML, outDir, "user", 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	called := false
+	_, err = Run(context.Background(), Config{
+		Options: Options{
+			RootDir:  "./testdata/concurrent/user",
+			RouteDir: "./testdata/concurrent/user",
+			DocPath:  "./testdata/concurrent/user/doc.go",
+		},
+		Emitters: []Emitter{
+			yamlEmitter,
+			&FileEmitter{Format: "title", Writer: buf},
+			EmitterFunc(func(t *openapi3.T) error {
+				called = true
+				return nil
+			}),
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	fileInfo, err := os.Stat(filepath.Join(outDir, "user.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fileInfo.Mode().Perm() != 0600 {
+		t.Fatalf("文件权限应该是 0600，实际是 %v", fileInfo.Mode().Perm())
+	}
+	if buf.String() != "user 服务" || !called {
+		t.Fatalf("自定义输出错误：%v %v", buf.String(), called)
+	}
+	if _, err = NewFileEmitter("unknown", outDir, "user", 0); !IsErrorKind(err, ErrorKindWrite) {
+		t.Fatalf("未注册的格式应该返回错误：%v", err)
+	}
+	// json 和 json-compact 输出到不同的文件，文件路径相同时在输出前报错
+	jsonEmitter, err := NewFileEmitter(FormatJSON, outDir, "user", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	compactEmitter, err := NewFileEmitter(FormatJSONCompact, outDir, "user", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if jsonEmitter.Path == compactEmitter.Path || filepath.Base(compactEmitter.Path) != "user.min.json" {
+		t.Fatalf("json-compact 的文件路径错误：%v", compactEmitter.Path)
+	}
+	compactEmitter.Path = jsonEmitter.Path
+	if err = Emit(&openapi3.T{}, jsonEmitter, compactEmitter); !IsErrorKind(err, ErrorKindWrite) {
+		t.Fatalf("输出文件重复应该返回错误：%v", err)
+	}
+	if _, err = os.Stat(jsonEmitter.Path); !os.IsNotExist(err) {
+		t.Fatalf("返回错误前不应该写入文件：%v", err)
+	}
+}
+
+func TestGenerateStable(t *testing.T) {
+	var want []byte
+	for i := 0; i < 20; i++ {
+		doc, err := Generate(context.Background(), Options{
+			RootDir:  "./testdata/stable",
+			RouteDir: "./testdata/stable",
+			DocPath:  "./testdata/stable/doc.go",
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		buf, err := yamlMarshal(doc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want == nil {
+			want = buf
+			// 标签按照注释顺序，参数按照注释顺序后接公共参数
+			operation := doc.Paths.Value("/v2/items").Get
+			if strings.Join(operation.Tags, ",") != "zeta,alpha,mid" {
+				t.Fatalf("标签顺序错误：%v", operation.Tags)
+			}
+			// 多个@router共用同一个方法时每个路由都生成
+			if id := doc.Paths.Value("/items").Get.OperationID; id != "listItems" || operation.OperationID != "listV2Items" {
+				t.Fatalf("共用方法的operationId错误：%v %v", id, operation.OperationID)
+			}
+			var names []string
+			for _, v := range operation.Parameters {
+				names = append(names, v.Value.Name)
+			}
+			if strings.Join(names, ",") != "page,size,X-Trace" {
+				t.Fatalf("参数顺序错误：%v", names)
+			}
+			continue
+		}
+		if !bytes.Equal(want, buf) {
+			t.Fatalf("第 %v 次生成结果不一致", i+1)
+		}
+	}
+}
+
+func TestGenerateExample(t *testing.T) {
+	doc, err := Generate(context.Background(), Options{
+		RootDir:  "./testdata/example",
+		RouteDir: "./testdata/example",
+		DocPath:  "./testdata/example/doc.go",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// baseline.json 是修改前的版本生成的结构体，字符串字段的示例值和默认值保持不变
+	buf, err := os.ReadFile("./testdata/example/baseline.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var want openapi3.Schema
+	if err = json.Unmarshal(buf, &want); err != nil {
+		t.Fatal(err)
+	}
+	props := doc.Components.Schemas["example.com.example.Item"].Value.Properties
+	for _, name := range sortedKeys(want.Properties) {
+		wantValue, gotValue := want.Properties[name].Value, props[name].Value
+		wantBuf, _ := json.Marshal([]interface{}{wantValue.Example, wantValue.Default})
+		gotBuf, _ := json.Marshal([]interface{}{gotValue.Example, gotValue.Default})
+		if !bytes.Equal(wantBuf, gotBuf) {
+			t.Fatalf("字段 %v 的示例值和默认值应该是 %s，实际是 %s", name, wantBuf, gotBuf)
+		}
+	}
+}
+
+func TestGenerateOpenAPI31(t *testing.T) {
+	opts := Options{
+		RootDir:  "./testdata/v31",
+		RouteDir: "./testdata/v31",
+		DocPath:  "./testdata/v31/doc.go",
+	}
+	// 3.0版本忽略3.1的注释并警告
+	var warnings []Diagnostic
+	opts.OnWarning = func(d Diagnostic) {
+		warnings = append(warnings, d)
+	}
+	doc, err := Generate(context.Background(), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if doc.OpenAPI != OpenAPIVersion30 || len(warnings) != 2 || warnings[0].Code != CodeVersion {
+		t.Fatalf("3.0版本错误：%v %v", doc.OpenAPI, warnings)
+	}
+	// 示例值按照字段类型输出，3.0和3.1版本一致
+	if example := doc.Components.Schemas["example.com.v31.Pet"].Value.Properties["id"].Value.Example; example != int64(1) {
+		t.Fatalf("整数字段的示例应该是数字：%#v", example)
+	}
+	opts.OpenAPIVersion = "3.1"
+	doc, err = Generate(context.Background(), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	buf, err := json.Marshal(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var rs struct {
+		OpenAPI string `json:"openapi"`
+		Info    struct {
+			Summary string `json:"summary"`
+			License struct {
+				Identifier string `json:"identifier"`
+			} `json:"license"`
+		} `json:"info"`
+		Components struct {
+			Schemas map[string]struct {
+				Properties map[string]map[string]interface{} `json:"properties"`
+			} `json:"schemas"`
+		} `json:"components"`
+	}
+	if err = json.Unmarshal(buf, &rs); err != nil {
+		t.Fatal(err)
+	}
+	if rs.OpenAPI != OpenAPIVersion31 || rs.Info.Summary == "" || rs.Info.License.Identifier != "Apache-2.0" {
+		t.Fatalf("3.1版本信息错误：%s", buf)
+	}
+	pet := rs.Components.Schemas["example.com.v31.Pet"].Properties
+	if pet["owner"]["$ref"] == nil || pet["owner"]["description"] != "主人" {
+		t.Fatalf("$ref 同级字段错误：%v", pet["owner"])
+	}
+	if examples, _ := pet["id"]["examples"].([]interface{}); len(examples) != 1 || pet["id"]["example"] != nil {
+		t.Fatalf("examples 错误：%v", pet["id"])
+	}
+	if _, err = Generate(context.Background(), Options{OpenAPIVersion: "2.0"}); !IsErrorKind(err, ErrorKindValidate) {
+		t.Fatalf("不支持的版本应该返回错误：%v", err)
+	}
+}
+
+func TestOpenAPI31Nullable(t *testing.T) {
+	o := &openapiHandle{t: &openapi3.T{
+		Components: &openapi3.Components{Schemas: openapi3.Schemas{
+			"A": {Value: &openapi3.Schema{Properties: openapi3.Schemas{
+				"name":  {Value: &openapi3.Schema{Type: "string", Nullable: true}},
+				"child": {Ref: "#/components/schemas/A", Value: &openapi3.Schema{Nullable: true}},
+			}}},
+		}},
+	}}
+	(&openapi31Handle{}).convert(o)
+	buf, err := json.Marshal(o.t.Components.Schemas["A"])
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"properties":{"child":{"anyOf":[{"$ref":"#/components/schemas/A"},{"type":"null"}]},"name":{"type":["string","null"]}}}`
+	if string(buf) != want {
+		t.Fatalf("nullable 转换错误：%s", buf)
+	}
+}
+
+func TestToSwagger(t *testing.T) {
+	doc, err := Generate(context.Background(), Options{
+		RootDir:  "./testdata/swagger",
+		RouteDir: "./testdata/swagger",
+		DocPath:  "./testdata/swagger/doc.go",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc.Components.Schemas["Pet"] = &openapi3.SchemaRef{Value: &openapi3.Schema{OneOf: openapi3.SchemaRefs{
+		{Ref: "#/components/schemas/example.com.swagger.User"},
+		{Value: &openapi3.Schema{Type: "string"}},
+	}}}
+	want, err := json.Marshal(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var warnings []Diagnostic
+	buf := &bytes.Buffer{}
+	emitter := &SwaggerEmitter{Format: FormatJSON, Writer: buf, OnWarning: func(d Diagnostic) {
+		warnings = append(warnings, d)
+	}}
+	if err = emitter.Emit(doc); err != nil {
+		t.Fatal(err)
+	}
+	// 转换不能修改原文档
+	if got, _ := json.Marshal(doc); !bytes.Equal(want, got) {
+		t.Fatal("转换修改了原文档")
+	}
+	codes := []string{CodeSwaggerServer, CodeSwaggerSchema, CodeSwaggerCookie, CodeSwaggerMediaType, CodeSwaggerMediaType}
+	if len(warnings) != len(codes) {
+		t.Fatalf("应该有 %v 个警告，实际是 %v", len(codes), warnings)
+	}
+	for i, v := range warnings {
+		if v.Code != codes[i] || v.Severity != SeverityWarning {
+			t.Fatalf("第 %v 个警告错误：%v", i, v)
+		}
+	}
+	var rs struct {
+		Swagger  string `json:"swagger"`
+		Host     string `json:"host"`
+		BasePath string `json:"basePath"`
+		Paths    map[string]map[string]struct {
+			Consumes   []string `json:"consumes"`
+			Produces   []string `json:"produces"`
+			Parameters []struct {
+				In   string `json:"in"`
+				Name string `json:"name"`
+			} `json:"parameters"`
+			Responses map[string]struct {
+				Schema map[string]interface{} `json:"schema"`
+			} `json:"responses"`
+		} `json:"paths"`
+	}
+	if err = json.Unmarshal(buf.Bytes(), &rs); err != nil {
+		t.Fatal(err)
+	}
+	if rs.Swagger != "2.0" || rs.Host != "api.example.com" || rs.BasePath != "/v1" {
+		t.Fatalf("swagger信息错误：%s", buf)
+	}
+	operation := rs.Paths["/user"]["post"]
+	var ins []string
+	for _, v := range operation.Parameters {
+		ins = append(ins, v.In+":"+v.Name)
+	}
+	if strings.Join(ins, ",") != "body:body,query:force" {
+		t.Fatalf("参数错误：%v", ins)
+	}
+	if strings.Join(operation.Consumes, ",") != "application/json" ||
+		strings.Join(operation.Produces, ",") != "application/json" ||
+		operation.Responses["200"].Schema["$ref"] != "#/definitions/example.com.swagger.User" {
+		t.Fatalf("请求或返回错误：%s", buf)
+	}
+	doc.OpenAPI = OpenAPIVersion31
+	if _, _, err = ToSwagger(doc); !IsErrorKind(err, ErrorKindValidate) {
+		t.Fatalf("3.1版本应该返回错误：%v", err)
+	}
+	// 3.1版本和swagger一起输出时，在写入任何文件前返回错误
+	outDir := t.TempDir()
+	_, err = Run(context.Background(), Config{
+		Options: Options{
+			RootDir:        "./testdata/swagger",
+			RouteDir:       "./testdata/swagger",
+			DocPath:        "./testdata/swagger/doc.go",
+			OpenAPIVersion: "3.1",
+		},
+		Emitters: []Emitter{
+			&FileEmitter{Format: FormatYAML, Path: filepath.Join(outDir, "openapi.yaml")},
+			&SwaggerEmitter{Format: FormatYAML, Path: filepath.Join(outDir, "swagger.yaml")},
+		},
+	})
+	if !IsErrorKind(err, ErrorKindValidate) {
+		t.Fatalf("3.1版本输出swagger应该返回错误：%v", err)
+	}
+	if entries, _ := os.ReadDir(outDir); len(entries) != 0 {
+		t.Fatalf("返回错误前不应该写入文件：%v", entries)
+	}
+}
+
+func TestGenerateModResolve(t *testing.T) {
+	doc, err := Generate(context.Background(), Options{
+		RootDir:  "./testdata/modreplace/app",
+		RouteDir: "./testdata/modreplace/app",
+		DocPath:  "./testdata/modreplace/app/doc.go",
+		ModCacheFS: fstest.MapFS{
+			"github.com/!acme/!types@v1.2.3/types.go": {Data: []byte("package types\n\ntype Item struct {\n\tCache string `json:\"cache\"`\n}\n")},
+			"example.com/new@v1.1.0/new.go":           {Data: []byte("package old\n\ntype Item struct {\n\tReplace string `json:\"replace\"`\n}\n")},
+		},
+		// 覆盖文件只用于项目文件系统，和模块缓存中路径相同的文件不受影响
+		Overlay: map[string][]byte{
+			"github.com/!acme/!types@v1.2.3/types.go": []byte("package types\n\ntype Item struct {\n\tOverlay string `json:\"overlay\"`\n}\n"),
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, field := range map[string]string{
+		"example.com.shared.dto.Item": "shared",
+		"github.com.Acme.Types.Item":  "cache",
+		"example.com.old.Item":        "replace",
+	} {
+		schema := doc.Components.Schemas[name]
+		if schema == nil || schema.Value.Properties[field] == nil {
+			t.Fatalf("模块结构体 %v 未生成：%v", name, sortedKeys(doc.Components.Schemas))
+		}
+	}
+	t.Setenv("GOMODCACHE", "")
+	t.Setenv("GOPATH", filepath.Join("a", "b")+string(filepath.ListSeparator)+"c")
+	if dir := (&modHandle{}).modAbsPath(); dir != filepath.Join("a", "b", "pkg", "mod") {
+		t.Fatalf("GOPATH 中的模块缓存目录错误：%v", dir)
+	}
+	t.Setenv("GOMODCACHE", "cache")
+	if dir := (&modHandle{}).modAbsPath(); dir != "cache" {
+		t.Fatalf("GOMODCACHE 模块缓存目录错误：%v", dir)
+	}
+}
+
+func TestGenerateWork(t *testing.T) {
+	opts := Options{
+		RootDir:  "./testdata/work/api",
+		RouteDir: "./testdata/work/api",
+		DocPath:  "./testdata/work/api/doc.go",
+	}
+	t.Setenv("GOWORK", "")
+	doc, err := Generate(context.Background(), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if schema := doc.Components.Schemas["example.com.dto.Order"]; schema == nil || schema.Value.Properties["id"] == nil {
+		t.Fatalf("go.work 中的模块结构体未生成：%v", sortedKeys(doc.Components.Schemas))
+	}
+	// 使用 fs.FS 时同样查找 go.work，不受环境变量 GOWORK 影响
+	t.Setenv("GOWORK", "off")
+	opts = Options{RootDir: "api", RouteDir: "api", DocPath: "api/doc.go", FS: os.DirFS("./testdata/work")}
+	if doc, err = Generate(context.Background(), opts); err != nil {
+		t.Fatal(err)
+	}
+	if doc.Components.Schemas["example.com.dto.Order"] == nil {
+		t.Fatalf("fs.FS 中 go.work 的模块结构体未生成：%v", sortedKeys(doc.Components.Schemas))
+	}
+	// Options.GoWork 为 off 时不使用 go.work
+	opts.GoWork = "off"
+	if doc, err = Generate(context.Background(), opts); err != nil {
+		t.Fatal(err)
+	}
+	if doc.Components.Schemas["example.com.dto.Order"] != nil {
+		t.Fatal("GoWork 为 off 时不应该使用 go.work")
+	}
+	opts.GoWork = "go.work"
+	if doc, err = Generate(context.Background(), opts); err != nil || doc.Components.Schemas["example.com.dto.Order"] == nil {
+		t.Fatalf("GoWork 指定的 go.work 未使用：%v", err)
+	}
+}
+
+func TestGenerateOperationId(t *testing.T) {
+	opts := Options{
+		RootDir:  "./testdata/operationid",
+		RouteDir: "./testdata/operationid",
+		DocPath:  "./testdata/operationid/doc.go",
+	}
+	_, err := Generate(context.Background(), opts)
+	var e *Error
+	if !errors.As(err, &e) || len(e.Diagnostics) != 1 || e.Diagnostics[0].Code != CodeOperationId ||
+		e.Diagnostics[0].Severity != SeverityError || e.Diagnostics[0].Pos.Line != 29 {
+		t.Fatalf("重复的operationId应该返回错误：%v", err)
+	}
+	buf, err := os.ReadFile("./testdata/operationid/route.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	buf = bytes.Replace(buf, []byte("createItem\n// @router: method=put"), []byte("updateItem\n// @router: method=put"), 1)
+	opts.Overlay = map[string][]byte{"./testdata/operationid/route.go": buf}
+	var warnings []Diagnostic
+	opts.OnWarning = func(d Diagnostic) {
+		warnings = append(warnings, d)
+	}
+	doc, err := Generate(context.Background(), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ids := []string{
+		doc.Paths.Value("/users").Get.OperationID,
+		doc.Paths.Value("/items").Get.OperationID,
+		doc.Paths.Value("/v2/items").Get.OperationID,
+		doc.Paths.Value("/items").Post.OperationID,
+		doc.Paths.Value("/items").Put.OperationID,
+	}
+	if strings.Join(ids, ",") != "userGetList,listItems,listV2Items,createItem,updateItem" {
+		t.Fatalf("operationId错误：%v", ids)
+	}
+	if len(warnings) != 0 {
+		t.Fatalf("operationId不重复时不应该警告：%v", warnings)
+	}
+	// 共用的注释指定operationId时同样添加后缀
+	opts.Overlay["./testdata/operationid/route.go"] = bytes.Replace(buf, []byte("// @summary: 列表\n"),
+		[]byte("// @summary: 列表\n// @operationId: fetch\n"), 1)
+	if doc, err = Generate(context.Background(), opts); err != nil {
+		t.Fatal(err)
+	}
+	if id1, id2 := doc.Paths.Value("/items").Get.OperationID, doc.Paths.Value("/v2/items").Get.OperationID; id1 != "fetchItems" ||
+		id2 != "fetchV2Items" {
+		t.Fatalf("共用注释的operationId错误：%v %v", id1, id2)
+	}
+	// 默认的operationId和其他路由重复时报错
+	opts.Overlay["./testdata/operationid/route.go"] = append(append([]byte{}, buf...),
+		[]byte("\n// @summary: 列表\n// @router: method=delete;path=/items\nfunc ListItems() {\n}\n")...)
+	if _, err = Generate(context.Background(), opts); !errors.As(err, &e) || len(e.Diagnostics) != 1 ||
+		e.Diagnostics[0].Code != CodeOperationId || e.Diagnostics[0].Pos.Line != 34 {
+		t.Fatalf("默认的operationId重复应该返回错误：%v", err)
+	}
+}
+
+func TestGenerateDeprecated(t *testing.T) {
+	doc, err := Generate(context.Background(), Options{
+		RootDir:  "./testdata/deprecated",
+		RouteDir: "./testdata/deprecated",
+		DocPath:  "./testdata/deprecated/doc.go",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	operation := doc.Paths.Value("/items").Get
+	if !operation.Deprecated || operation.Extensions["x-sunset"] != "2026-12-31" ||
+		operation.Description != "获取列表\n\nDeprecated: 使用 /v2/items" || !operation.Parameters[0].Value.Deprecated {
+		t.Fatalf("@deprecated 错误：%v %v %v", operation.Deprecated, operation.Extensions, operation.Description)
+	}
+	operation = doc.Paths.Value("/legacy").Get
+	if !operation.Deprecated || operation.Description != "Deprecated: 使用 Old 替代。" {
+		t.Fatalf("Deprecated: 注释错误：%v %v", operation.Deprecated, operation.Description)
+	}
+	if operation = doc.Paths.Value("/bare").Get; !operation.Deprecated || operation.Description != "" {
+		t.Fatalf("@deprecated 错误：%v %v", operation.Deprecated, operation.Description)
+	}
+	properties := doc.Components.Schemas["example.com.deprecated.Item"].Value.Properties
+	if !properties["name"].Value.Deprecated || !properties["title"].Value.Deprecated ||
+		properties["keep"].Value.Deprecated || !properties["owner"].Value.Deprecated ||
+		properties["owner"].Value.AllOf[0].Ref != "#/components/schemas/example.com.deprecated.Owner" {
+		t.Fatal("结构体字段废弃错误")
+	}
+}
+
+func TestGenerateResponseHeader(t *testing.T) {
+	doc, err := Generate(context.Background(), Options{
+		RootDir:  "./testdata/resheader",
+		RouteDir: "./testdata/resheader",
+		DocPath:  "./testdata/resheader/doc.go",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	responses := doc.Paths.Value("/items").Post.Responses
+	created := responses.Value("201").Value.Headers
+	if location := created["Location"].Value; location == nil || !location.Required ||
+		location.Schema.Value.Type != "string" || location.Schema.Value.Example != "/items/1" {
+		t.Fatalf("Location 返回头错误：%v", created["Location"])
+	}
+	if etag := created["ETag"].Value; etag == nil || etag.Schema.Value.Type != "string" {
+		t.Fatalf("未指定类型的返回头应该是 string：%v", created["ETag"])
+	}
+	if responses.Value("200") != nil {
+		t.Fatal("存在201返回时不应该添加200返回")
+	}
+	for _, status := range []string{"201", "429"} {
+		header := responses.Value(status).Value.Headers["X-RateLimit-Remaining"]
+		if header == nil || header.Value.Schema.Value.Format != "int32" {
+			t.Fatalf("%v 中的公共返回头错误：%v", status, header)
+		}
+	}
+	if responses.Value("429").Value.Headers["Retry-After"] == nil || responses.Value("201").Value.Headers["Retry-After"] != nil {
+		t.Fatal("指定状态的公共返回头错误")
+	}
+}
+
+func TestGenerateComponents(t *testing.T) {
+	opts := Options{
+		RootDir:  "./testdata/components",
+		RouteDir: "./testdata/components",
+		DocPath:  "./testdata/components/doc.go",
+	}
+	doc, err := Generate(context.Background(), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	list := doc.Paths.Value("/items").Get
+	if len(list.Parameters) != 1 || list.Parameters[0].Ref != "#/components/parameters/PageSize" {
+		t.Fatalf("引用参数错误：%v", list.Parameters)
+	}
+	if header := list.Responses.Value("200").Value.Headers["RateLimit"]; header == nil || header.Ref != "#/components/headers/RateLimit" {
+		t.Fatalf("引用返回头错误：%v", header)
+	}
+	if ref := list.Responses.Value("404").Ref; ref != "#/components/responses/NotFound" {
+		t.Fatalf("公共返回引用错误：%v", ref)
+	}
+	create := doc.Paths.Value("/items").Post
+	if create.RequestBody.Ref != "#/components/requestBodies/CreateItem" || !doc.Components.RequestBodies["CreateItem"].Value.Required {
+		t.Fatalf("引用请求错误：%v", create.RequestBody)
+	}
+	if example := create.Responses.Value("201").Value.Content["application/json"].Examples["ItemExample"]; example == nil ||
+		example.Ref != "#/components/examples/ItemExample" {
+		t.Fatalf("引用示例错误：%v", example)
+	}
+	// 指定状态添加返回头时复制引用的返回，不修改组件
+	notFound := create.Responses.Value("404")
+	if notFound.Ref != "" || notFound.Value.Headers["X-Request-Id"] == nil || doc.Components.Responses["NotFound"].Value.Headers != nil {
+		t.Fatalf("引用的返回添加返回头错误：%v", notFound)
+	}
+	if _, _, err = ToSwagger(doc); err != nil {
+		t.Fatal(err)
+	}
+	// 引用不存在的组件
+	buf, err := os.ReadFile("./testdata/components/route.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	opts.Overlay = map[string][]byte{
+		"./testdata/components/route.go": bytes.Replace(buf, []byte("ref=PageSize"), []byte("ref=Page"), 1),
+	}
+	_, err = Generate(context.Background(), opts)
+	var genErr *Error
+	if !errors.As(err, &genErr) || len(genErr.Diagnostics) != 1 || genErr.Diagnostics[0].Code != CodeRefNotFound ||
+		genErr.Diagnostics[0].Pos.Line != 19 {
+		t.Fatalf("引用不存在的组件应该报错：%v", err)
+	}
+	// 组件中引用不存在的组件，诊断位置为组件注释所在行
+	buf, err = os.ReadFile("./testdata/components/doc.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	opts.Overlay = map[string][]byte{
+		"./testdata/components/doc.go": bytes.Replace(buf, []byte("examples=PageExample"), []byte("examples=Page"), 1),
+	}
+	_, err = Generate(context.Background(), opts)
+	// 引用该组件的路由同时报错
+	if !errors.As(err, &genErr) || len(genErr.Diagnostics) != 2 || genErr.Diagnostics[0].Code != CodeRefNotFound ||
+		genErr.Diagnostics[0].Key != "@components.parameters" || genErr.Diagnostics[0].Pos.Line != 7 {
+		t.Fatalf("组件引用不存在的组件应该报错：%v", err)
+	}
+}
+
+func TestGenerateResponseStatus(t *testing.T) {
+	opts := Options{
+		RootDir:  "./testdata/status",
+		RouteDir: "./testdata/status",
+		DocPath:  "./testdata/status/doc.go",
+	}
+	statusList := func(doc *openapi3.T, method string) string {
+		operation := doc.Paths.Value("/items").GetOperation(method)
+		return strings.Join(sortedKeys(operation.Responses.Map()), ",")
+	}
+	doc, err := Generate(context.Background(), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for method, want := range map[string]string{
+		"DELETE": "204,4XX,5XX",
+		"PUT":    "5XX,default",
+		"GET":    "200,404,5XX",
+	} {
+		if got := statusList(doc, method); got != want {
+			t.Fatalf("%v 的返回状态应该是 %v，实际为 %v", method, want, got)
+		}
+	}
+	opts.FallbackResponse = &FallbackResponse{Status: "2XX", Description: "成功"}
+	if doc, err = Generate(context.Background(), opts); err != nil {
+		t.Fatal(err)
+	}
+	if got := statusList(doc, "GET"); got != "2XX,404,5XX" ||
+		*doc.Paths.Value("/items").Get.Responses.Value("2XX").Value.Description != "成功" {
+		t.Fatalf("配置的默认返回错误：%v", got)
+	}
+	opts.FallbackResponse = &FallbackResponse{}
+	if doc, err = Generate(context.Background(), opts); err != nil {
+		t.Fatal(err)
+	}
+	if got := statusList(doc, "GET"); got != "404,5XX" {
+		t.Fatalf("不添加默认返回时返回状态错误：%v", got)
+	}
+	opts.FallbackResponse = &FallbackResponse{Status: "600"}
+	if _, err = Generate(context.Background(), opts); !IsErrorKind(err, ErrorKindValidate) {
+		t.Fatalf("默认返回的状态码错误时应该报错：%v", err)
+	}
+	// 状态码范围swagger2.0无法表达
+	opts.FallbackResponse = nil
+	if doc, err = Generate(context.Background(), opts); err != nil {
+		t.Fatal(err)
+	}
+	swagger, diags, err := ToSwagger(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(diags) == 0 || diags[0].Code != CodeSwaggerStatus || swagger.Paths["/items"].Delete.Responses["4XX"] != nil {
+		t.Fatalf("swagger2.0应该移除状态码范围：%v", diags)
+	}
+	// 错误的状态码
+	buf, err := os.ReadFile("./testdata/status/route.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	opts.Overlay = map[string][]byte{
+		"./testdata/status/route.go": bytes.Replace(buf, []byte("status=4XX"), []byte("status=4xx"), 1),
+	}
+	_, err = Generate(context.Background(), opts)
+	var genErr *Error
+	if !errors.As(err, &genErr) || len(genErr.Diagnostics) != 1 || genErr.Diagnostics[0].Code != CodeNotStatus {
+		t.Fatalf("错误的状态码应该报错：%v", err)
+	}
+	for _, status := range []string{"0200", "+200", "-1", "20", "2000"} {
+		opts.Overlay["./testdata/status/route.go"] = bytes.Replace(buf, []byte("status=4XX"), []byte("status="+status), 1)
+		if _, err = Generate(context.Background(), opts); !errors.As(err, &genErr) || len(genErr.Diagnostics) != 1 ||
+			genErr.Diagnostics[0].Code != CodeNotStatus {
+			t.Fatalf("状态码 %v 应该报错：%v", status, err)
+		}
+	}
+	opts.Overlay = nil
+	opts.FallbackResponse = &FallbackResponse{Status: "+200"}
+	if _, err = Generate(context.Background(), opts); !IsErrorKind(err, ErrorKindValidate) {
+		t.Fatalf("默认返回的状态码错误时应该报错：%v", err)
+	}
+}
+
+func TestGenerateMediaType(t *testing.T) {
+	opts := Options{
+		RootDir:  "./testdata/mediatype",
+		RouteDir: "./testdata/mediatype",
+		DocPath:  "./testdata/mediatype/doc.go",
+	}
+	doc, err := Generate(context.Background(), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	upload := doc.Paths.Value("/files").Post
+	for _, in := range []string{"image/*", "application/octet-stream"} {
+		schema := upload.RequestBody.Value.Content[in].Schema.Value
+		if schema == nil || schema.Type != "string" || schema.Format != "binary" {
+			t.Fatalf("%v 默认应该是二进制：%v", in, schema)
+		}
+	}
+	if upload.Responses.Value("200").Value.Content["application/vnd.acme.v2+json"].Schema.Ref == "" ||
+		upload.Responses.Value("4XX").Value.Content["application/problem+json"].Schema.Ref == "" {
+		t.Fatal("自定义媒体类型应该使用content的结构体")
+	}
+	content := doc.Paths.Value("/files").Get.Responses.Value("200").Value.Content
+	for in, format := range map[string]string{
+		"application/pdf":      "binary",
+		"text/csv":             "",
+		"text/event-stream":    "",
+		"application/x-ndjson": "",
+	} {
+		schema := content[in].Schema.Value
+		if schema == nil || schema.Type != "string" || schema.Format != format {
+			t.Fatalf("%v 默认的schema错误：%v", in, schema)
+		}
+	}
+	// 错误的媒体类型
+	buf, err := os.ReadFile("./testdata/mediatype/route.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	opts.Overlay = map[string][]byte{
+		"./testdata/mediatype/route.go": bytes.Replace(buf, []byte("in=image/*"), []byte("in=*/png"), 1),
+	}
+	_, err = Generate(context.Background(), opts)
+	var genErr *Error
+	if !errors.As(err, &genErr) || len(genErr.Diagnostics) != 1 || genErr.Diagnostics[0].Code != CodeNotMediaType {
+		t.Fatalf("错误的媒体类型应该报错：%v", err)
+	}
+}
+
+func TestGenerateExtension(t *testing.T) {
+	doc, err := Generate(context.Background(), Options{
+		RootDir:  "./testdata/extension",
+		RouteDir: "./testdata/extension",
+		DocPath:  "./testdata/extension/doc.go",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if logo, _ := doc.Extensions["x-logo"].(map[string]interface{}); logo["url"] != "https://example.com/logo.png" ||
+		doc.Extensions["x-owner"] != "platform" {
+		t.Fatalf("文档的扩展字段错误：%v", doc.Extensions)
+	}
+	upload := doc.Paths.Value("/upload").Post
+	if upload.ExternalDocs == nil || upload.ExternalDocs.URL != "https://wiki.example.com/upload" ||
+		upload.ExternalDocs.Description != "上传手册" {
+		t.Fatalf("路由的扩展文档错误：%v", upload.ExternalDocs)
+	}
+	if upload.Servers == nil || len(*upload.Servers) != 1 || (*upload.Servers)[0].URL != "https://cdn.example.com" {
+		t.Fatalf("路由的服务地址错误：%v", upload.Servers)
+	}
+	integration, _ := upload.Extensions["x-amazon-apigateway-integration"].(map[string]interface{})
+	if upload.Extensions["x-ratelimit"] != float64(100) || integration["type"] != "http_proxy" {
+		t.Fatalf("路由的扩展字段错误：%v", upload.Extensions)
+	}
+	swagger, diags, err := ToSwagger(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(diags) != 1 || diags[0].Code != CodeSwaggerServer || swagger.Paths["/upload"].Post.Extensions["x-ratelimit"] == nil {
+		t.Fatalf("swagger2.0转换错误：%v", diags)
+	}
+}
+
+func TestGenerateParamStyle(t *testing.T) {
+	doc, err := Generate(context.Background(), Options{
+		RootDir:  "./testdata/paramstyle",
+		RouteDir: "./testdata/paramstyle",
+		DocPath:  "./testdata/paramstyle/doc.go",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	params := doc.Paths.Value("/search").Get.Parameters
+	ids := params.GetByInAndName("query", "ids")
+	if ids == nil || ids.Style != "form" || ids.Explode == nil || !*ids.Explode {
+		t.Fatalf("ids 参数错误：%v", ids)
+	}
+	items := ids.Schema.Value.Items
+	if ids.Schema.Value.Type != "array" || items == nil || items.Value.Type != "integer" || items.Value.Format != "int64" ||
+		len(items.Value.Enum) != 3 || items.Value.Enum[0] != float64(1) {
+		t.Fatalf("ids 数组类型错误：%v", ids.Schema.Value)
+	}
+	if example, _ := ids.Schema.Value.Example.([]interface{}); len(example) != 2 || example[1] != int64(2) {
+		t.Fatalf("ids 示例错误：%v", ids.Schema.Value.Example)
+	}
+	tags := params.GetByInAndName("query", "tags")
+	if tags == nil || tags.Style != "pipeDelimited" || tags.Explode == nil || *tags.Explode ||
+		tags.Schema.Value.Items.Value.Type != "string" {
+		t.Fatalf("tags 参数错误：%v", tags)
+	}
+	filter := params.GetByInAndName("query", "filter")
+	if filter == nil || filter.Style != "deepObject" || filter.Schema.Ref != "#/components/schemas/example.com.paramstyle.Filter" {
+		t.Fatalf("filter 参数错误：%v", filter)
+	}
+	q := params.GetByInAndName("query", "q")
+	if q == nil || !q.AllowEmptyValue || !q.AllowReserved {
+		t.Fatalf("q 参数错误：%v", q)
+	}
+	swagger, diags, err := ToSwagger(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(diags) != 1 || diags[0].Code != CodeSwaggerSchema || len(swagger.Paths["/search"].Get.Parameters) != 3 {
+		t.Fatalf("swagger2.0应该移除对象参数：%v", diags)
+	}
+}
+
+func TestGenerateStructParam(t *testing.T) {
+	opts := Options{
+		RootDir:  "./testdata/structparam",
+		RouteDir: "./testdata/structparam",
+		DocPath:  "./testdata/structparam/doc.go",
+	}
+	doc, err := Generate(context.Background(), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	params := doc.Paths.Value("/users/{id}/items").Get.Parameters
+	var names []string
+	for _, v := range params {
+		names = append(names, v.Value.In+"."+v.Value.Name)
+	}
+	if got := strings.Join(names, ","); got != "path.id,query.page,query.page_size,query.keyword,query.status,query.sort,header.X-Trace-Id" {
+		t.Fatalf("结构体展开的参数错误：%v", got)
+	}
+	page := params.GetByInAndName("query", "page")
+	if !page.Required || page.Description != "页码" || page.Schema.Value.Type != "integer" || page.Schema.Value.Description != "" {
+		t.Fatalf("binding:\"required\" 的参数错误：%v", page)
+	}
+	if size := params.GetByInAndName("query", "page_size"); size.Required || size.Schema.Value.Default != int64(20) {
+		t.Fatalf("page_size 参数错误：%v", size)
+	}
+	if keyword := params.GetByInAndName("query", "keyword"); keyword.Schema.Value.MaxLength == nil || *keyword.Schema.Value.MaxLength != 20 {
+		t.Fatalf("keyword 参数错误：%v", keyword)
+	}
+	if status := params.GetByInAndName("query", "status"); !status.Required || status.Schema.Value.Type != "array" {
+		t.Fatalf("status 参数错误：%v", status)
+	}
+	if id := params.GetByInAndName("path", "id"); !id.Required || id.Schema.Value.Format != "int64" {
+		t.Fatalf("路径参数错误：%v", id)
+	}
+	// 结构体不存在
+	buf, err := os.ReadFile("./testdata/structparam/route.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	opts.Overlay = map[string][]byte{
+		"./testdata/structparam/route.go": bytes.Replace(buf, []byte("in=header; content=structparam.ListFilter"),
+			[]byte("in=header; content=structparam.Header"), 1),
+	}
+	_, err = Generate(context.Background(), opts)
+	var genErr *Error
+	if !errors.As(err, &genErr) || len(genErr.Diagnostics) != 1 || genErr.Diagnostics[0].Code != CodeRefNotFound {
+		t.Fatalf("参数的结构体不存在时应该报错：%v", err)
+	}
+}
+
+func TestGenerateCallback(t *testing.T) {
+	opts := Options{
+		RootDir:  "./testdata/callback",
+		RouteDir: "./testdata/callback",
+		DocPath:  "./testdata/callback/doc.go",
+	}
+	var warnings []Diagnostic
+	opts.OnWarning = func(d Diagnostic) {
+		warnings = append(warnings, d)
+	}
+	doc, err := Generate(context.Background(), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	callback := doc.Paths.Value("/exports").Post.Callbacks["exportDone"]
+	if callback == nil || callback.Value.Value("{$request.body#/callbackUrl}") == nil {
+		t.Fatalf("回调错误：%v", callback)
+	}
+	operation := callback.Value.Value("{$request.body#/callbackUrl}").Post
+	if operation == nil || operation.Summary != "导出完成" ||
+		operation.RequestBody.Value.Content["application/json"].Schema.Ref != "#/components/schemas/example.com.callback.ExportDone" ||
+		operation.Responses.Value("200") == nil {
+		t.Fatalf("回调的操作错误：%v", operation)
+	}
+	if doc.Extensions["webhooks"] != nil || len(warnings) != 1 || warnings[0].Code != CodeVersion {
+		t.Fatalf("3.0版本应该忽略webhooks并警告：%v", warnings)
+	}
+	if _, diags, err := ToSwagger(doc); err != nil || len(diags) != 1 || diags[0].Code != CodeSwaggerCallback {
+		t.Fatalf("swagger2.0应该移除回调：%v %v", diags, err)
+	}
+	// 回调的operationId和路由的重复
+	routeBuf, err := os.ReadFile("./testdata/callback/route.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	opts.Overlay = map[string][]byte{
+		"./testdata/callback/route.go": bytes.Replace(routeBuf, []byte("summary=导出完成"),
+			[]byte("summary=导出完成; operationId=exportDone\n// @operationId: exportDone"), 1),
+	}
+	var routeErr *Error
+	if _, err = Generate(context.Background(), opts); !errors.As(err, &routeErr) || len(routeErr.Diagnostics) != 2 ||
+		routeErr.Diagnostics[1].Code != CodeOperationId || routeErr.Diagnostics[1].Pos.Line != 19 {
+		t.Fatalf("回调的operationId重复应该报错：%v", err)
+	}
+	opts.Overlay = nil
+	opts.OpenAPIVersion = OpenAPIVersion31
+	if doc, err = Generate(context.Background(), opts); err != nil {
+		t.Fatal(err)
+	}
+	webhooks, _ := doc.Extensions["webhooks"].(map[string]*openapi3.PathItem)
+	if webhooks["order.paid"] == nil || webhooks["order.paid"].Post == nil {
+		t.Fatalf("3.1版本的webhooks错误：%v", doc.Extensions["webhooks"])
+	}
+	paid := webhooks["order.paid"].Post
+	if paid.Summary != "订单已支付" || !paid.RequestBody.Value.Required || paid.Responses.Value("204") == nil || paid.Responses.Value("200") != nil {
+		t.Fatalf("webhook的操作错误：%v", paid)
+	}
+	if doc.Components.Schemas["example.com.callback.OrderPaid"] == nil {
+		t.Fatal("webhook使用的结构体未生成")
+	}
+	// webhook引用不存在的组件，诊断位置为@webhooks注释所在行
+	buf, err := os.ReadFile("./testdata/callback/doc.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	opts.Overlay = map[string][]byte{
+		"./testdata/callback/doc.go": bytes.Replace(buf, []byte("status=204; desc=接收成功"), []byte("status=204; ref=Accepted"), 1),
+	}
+	var genErr *Error
+	if _, err = Generate(context.Background(), opts); !errors.As(err, &genErr) || len(genErr.Diagnostics) != 1 ||
+		genErr.Diagnostics[0].Code != CodeRefNotFound || genErr.Diagnostics[0].Key != "@webhooks" || genErr.Diagnostics[0].Pos.Line != 4 {
+		t.Fatalf("webhook引用不存在的组件应该报错：%v", err)
+	}
+}
+
+func TestGenerateMapSchema(t *testing.T) {
+	opts := Options{
+		RootDir:  "./testdata/mapschema",
+		RouteDir: "./testdata/mapschema",
+		DocPath:  "./testdata/mapschema/doc.go",
+	}
+	doc, err := Generate(context.Background(), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	props := doc.Components.Schemas["example.com.mapschema.Stats"].Value.Properties
+	for name, prop := range props {
+		if name != "value" && (prop.Value.Type != "object" || len(prop.Value.Properties) != 0) {
+			t.Fatalf("字段 %v 应该是没有properties的对象：%v", name, prop.Value)
+		}
+	}
+	if props["counts"].Value.AdditionalProperties.Schema.Value.Type != "integer" ||
+		props["tags"].Value.AdditionalProperties.Schema.Ref != "#/components/schemas/example.com.mapschema.Tag" ||
+		props["groups"].Value.AdditionalProperties.Schema.Value.Items.Ref != "#/components/schemas/example.com.mapschema.Tag" {
+		t.Fatal("map的值应该使用additionalProperties")
+	}
+	if props["counts"].Value.Extensions[mapKeyTypeExtension] != nil || props["scores"].Value.Extensions[mapKeyTypeExtension] != "integer" ||
+		props["nested"].Value.AdditionalProperties.Schema.Value.Extensions[mapKeyTypeExtension] != "boolean" {
+		t.Fatal("map的key类型错误")
+	}
+	for _, name := range []string{"extra", "meta"} {
+		if has := props[name].Value.AdditionalProperties.Has; has == nil || !*has {
+			t.Fatalf("字段 %v 应该是自由对象", name)
+		}
+	}
+	if value := props["value"].Value; value.Type != "" || value.Format != "" {
+		t.Fatalf("任意类型不应该限制类型：%v", value)
+	}
+	opts.OpenAPIVersion = OpenAPIVersion31
+	if doc, err = Generate(context.Background(), opts); err != nil {
+		t.Fatal(err)
+	}
+	scores := doc.Components.Schemas["example.com.mapschema.Stats"].Value.Properties["scores"].Value
+	if names, _ := scores.Extensions["propertyNames"].(map[string]interface{}); names["pattern"] != mapKeyPatterns["integer"] {
+		t.Fatalf("3.1版本的propertyNames错误：%v", scores.Extensions)
+	}
+}
+
+func TestGenerateTypeMapping(t *testing.T) {
+	opts := Options{
+		RootDir:  "./testdata/typemapping",
+		RouteDir: "./testdata/typemapping",
+		DocPath:  "./testdata/typemapping/doc.go",
+	}
+	var warnings []Diagnostic
+	opts.OnWarning = func(d Diagnostic) {
+		warnings = append(warnings, d)
+	}
+	doc, err := Generate(context.Background(), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	props := doc.Components.Schemas["example.com.typemapping.Order"].Value.Properties
+	// 不符合格式的默认值不输出并警告
+	if props["updated_at"].Value.Default != nil || props["created_at"].Value.Example != "2024-02-20T14:21:13Z" ||
+		len(warnings) != 1 || warnings[0].Code != CodeFormatValue || warnings[0].Key != "default" || warnings[0].Pos.Line == 0 {
+		t.Fatalf("类型映射格式错误的值应该忽略并警告：%v %v", props["updated_at"].Value.Default, warnings)
+	}
+	for name, want := range map[string][3]string{
+		"created_at": {"string", "date-time"},
+		"timeout":    {"integer", "int64"},
+		"paid_at":    {"string", "date-time", "nullable"},
+		"avatar":     {"string", "byte"},
+		"raw":        {"", ""},
+		"ip":         {"string", ""},
+		"total":      {"integer", "", "nullable"},
+	} {
+		schema := props[name].Value
+		if schema.Type != want[0] || schema.Format != want[1] || schema.Nullable != (want[2] == "nullable") {
+			t.Fatalf("字段 %v 的类型映射错误：%v %v %v", name, schema.Type, schema.Format, schema.Nullable)
+		}
+	}
+	if props["timeout"].Value.Default != int64(30) || props["times"].Value.Items.Value.Format != "date-time" {
+		t.Fatal("映射类型的默认值或者数组元素错误")
+	}
+	if props["price"].Ref != "#/components/schemas/example.com.typemapping.Money" {
+		t.Fatalf("结构体应该使用$ref：%v", props["price"].Ref)
+	}
+	// 没有json序列化方法的类型不映射，和其他无法解析的类型一样
+	for name, types := range map[string]string{
+		"remark":   "database/sql.NullString",
+		"count":    "database/sql.NullInt64",
+		"callback": "net/url.URL",
+	} {
+		if schema := props[name].Value; schema.Format != types || schema.Nullable {
+			t.Fatalf("字段 %v 不应该使用类型映射：%v %v", name, schema.Format, schema.Nullable)
+		}
+	}
+	// 配置文件中的映射覆盖结构体，配置文件和项目文件一样支持Overlay
+	opts.TypeMappingPath = "./testdata/typemapping/types.yaml"
+	opts.Overlay = map[string][]byte{
+		"./testdata/typemapping/types.yaml": []byte("example.com/typemapping.Money:\n  type: string\n  format: money\n  description: 金额\n"),
+	}
+	if doc, err = Generate(context.Background(), opts); err != nil {
+		t.Fatal(err)
+	}
+	price := doc.Components.Schemas["example.com.typemapping.Order"].Value.Properties["price"]
+	if price.Ref != "" || price.Value.Type != "string" || price.Value.Format != "money" || price.Value.Description != "价格" {
+		t.Fatalf("自定义类型映射错误：%v", price.Value)
+	}
+	opts.TypeMappingPath = "./testdata/typemapping/missing.yaml"
+	if _, err = Generate(context.Background(), opts); !IsErrorKind(err, ErrorKindValidate) {
+		t.Fatalf("类型映射文件不存在应该返回错误：%v", err)
+	}
+	opts.TypeMappingPath, opts.Overlay = "", nil
+	opts.TypeMappings = map[string]TypeMapping{"time.Time": {Type: "date"}}
+	if _, err = Generate(context.Background(), opts); !IsErrorKind(err, ErrorKindValidate) {
+		t.Fatalf("类型映射的类型错误应该返回验证错误：%v", err)
+	}
+}
+
+func TestGenerateJsonTag(t *testing.T) {
+	opts := Options{
+		RootDir:  "./testdata/jsontag",
+		RouteDir: "./testdata/jsontag",
+		DocPath:  "./testdata/jsontag/doc.go",
+	}
+	var warnings []Diagnostic
+	opts.OnWarning = func(d Diagnostic) {
+		warnings = append(warnings, d)
+	}
+	doc, err := Generate(context.Background(), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	user := doc.Components.Schemas["example.com.jsontag.User"].Value
+	for name, want := range map[string][2]string{
+		"id":     {"string", "int64"},
+		"score":  {"string", "float64"},
+		"active": {"string", "bool"},
+		"code":   {"string", ""},
+	} {
+		if schema := user.Properties[name].Value; schema.Type != want[0] || schema.Format != want[1] {
+			t.Fatalf("字段 %v 的string选项错误：%v %v", name, schema.Type, schema.Format)
+		}
+	}
+	if user.Properties["id"].Value.Example != "1001" {
+		t.Fatalf("string选项的示例应该是字符串：%v", user.Properties["id"].Value.Example)
+	}
+	if user.Properties["Age"] == nil || user.Properties["-"] == nil || user.Properties["Ignore"] != nil || user.Properties["Dash"] != nil {
+		t.Fatal("json标签的名称错误")
+	}
+	if user.Properties["created_by"] == nil || user.Properties["updated_by"] == nil || user.Properties["Audit"] != nil ||
+		user.Properties["source"] == nil || user.Properties["Meta"] != nil {
+		t.Fatal("inline选项应该展开字段")
+	}
+	// 不是结构体的inline字段作为普通字段并警告
+	if extra := user.Properties["extra"]; extra == nil || extra.Value.Type != "object" || len(warnings) != 1 ||
+		warnings[0].Code != CodeInline || warnings[0].Pos.Line == 0 {
+		t.Fatalf("map的inline选项应该保留字段并警告：%v", warnings)
+	}
+	if strings.Join(user.Required, ",") != "phone" {
+		t.Fatalf("默认只使用required标签：%v", user.Required)
+	}
+	opts.RequiredPolicy = RequiredPolicyOmitempty
+	if doc, err = Generate(context.Background(), opts); err != nil {
+		t.Fatal(err)
+	}
+	user = doc.Components.Schemas["example.com.jsontag.User"].Value
+	if required := strings.Join(user.Required, ","); required != "id,active,code,name,phone,-,created_by,source,extra" {
+		t.Fatalf("omitempty策略的必填字段错误：%v", required)
+	}
+	opts.RequiredPolicy = "all"
+	if _, err = Generate(context.Background(), opts); !IsErrorKind(err, ErrorKindValidate) {
+		t.Fatalf("必填策略错误应该返回验证错误：%v", err)
+	}
+}
+
+func TestGenerateNullable(t *testing.T) {
+	opts := Options{
+		RootDir:  "./testdata/nullable",
+		RouteDir: "./testdata/nullable",
+		DocPath:  "./testdata/nullable/doc.go",
+	}
+	doc, err := Generate(context.Background(), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	props := doc.Components.Schemas["example.com.nullable.PatchUser"].Value.Properties
+	for name, want := range map[string]bool{
+		"name":     true,
+		"age":      false,
+		"tags":     true,
+		"nickname": false,
+		"remark":   true,
+		"extra":    false,
+	} {
+		if props[name].Value.Nullable != want {
+			t.Fatalf("字段 %v 的nullable应该是 %v", name, want)
+		}
+	}
+	address := props["address"]
+	if address.Ref != "" || !address.Value.Nullable || len(address.Value.AllOf) != 1 ||
+		address.Value.AllOf[0].Ref != "#/components/schemas/example.com.nullable.Address" {
+		t.Fatalf("3.0版本指针结构体应该使用allOf：%v", address.Value)
+	}
+	opts.OpenAPIVersion = OpenAPIVersion31
+	if doc, err = Generate(context.Background(), opts); err != nil {
+		t.Fatal(err)
+	}
+	buf, err := json.Marshal(doc.Components.Schemas["example.com.nullable.PatchUser"])
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		`"name":{"description":"名称","type":["string","null"]}`,
+		`"address":{"anyOf":[{"$ref":"#/components/schemas/example.com.nullable.Address"},{"type":"null"}],"description":"地址"}`,
+	} {
+		if !strings.Contains(string(buf), want) {
+			t.Fatalf("3.1版本nullable错误，缺少 %v：%s", want, buf)
+		}
+	}
+	opts.OpenAPIVersion = ""
+	opts.PointerNotNullable = true
+	if doc, err = Generate(context.Background(), opts); err != nil {
+		t.Fatal(err)
+	}
+	props = doc.Components.Schemas["example.com.nullable.PatchUser"].Value.Properties
+	if props["name"].Value.Nullable || props["address"].Ref == "" || !props["remark"].Value.Nullable {
+		t.Fatal("关闭指针nullable后只有标签生效")
+	}
+}
+
+func TestGenerateGeneric(t *testing.T) {
+	doc, err := Generate(context.Background(), Options{
+		RootDir:  "./testdata/generic",
+		RouteDir: "./testdata/generic",
+		DocPath:  "./testdata/generic/doc.go",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	prefix := "#/components/schemas/example.com.generic."
+	for path, want := range map[string]string{
+		"/user":    "ResultGenericUser",
+		"/users":   "ResultPageUser",
+		"/pairs":   "PairUserRole",
+		"/collide": "ResultUser",
+	} {
+		for _, operation := range doc.Paths.Value(path).Operations() {
+			if ref := operation.Responses.Value("200").Value.Content["application/json"].Schema.Ref; ref != prefix+want {
+				t.Fatalf("路由 %v 的泛型返回错误：%v", path, ref)
+			}
+		}
+	}
+	schemas := doc.Components.Schemas
+	// 和同包的结构体重名时实例的类型参数加上包名
+	if schemas["example.com.generic.ResultUser"].Value.Properties["other"] == nil ||
+		schemas["example.com.generic.ResultGenericUser"].Value.Properties["data"].Ref != prefix+"User" ||
+		schemas["example.com.generic.PageUser"].Value.Properties["list"].Value.Items.Ref != prefix+"User" ||
+		schemas["example.com.generic.PairUserRole"].Value.Properties["value"].Ref != prefix+"Role" {
+		t.Fatal("泛型实例的字段类型错误")
+	}
+	batch := schemas["example.com.generic.Batch"].Value.Properties
+	if batch["result"].Ref != prefix+"ResultGenericUser" || batch["nested"].Ref != prefix+"ResultResultUser" ||
+		batch["roles"].Value.Items.Ref != prefix+"Role" || batch["pair"].Value.AllOf[0].Ref != prefix+"PairStringUser" {
+		t.Fatal("字段中的泛型实例错误")
+	}
+	// 不同包的同名类型参数使用不同的实例
+	if batch["a"].Ref != prefix+"ResultAUser" || batch["b"].Ref != prefix+"ResultBUser" ||
+		schemas["example.com.generic.ResultAUser"].Value.Properties["data"].Ref != prefix+"a.User" ||
+		schemas["example.com.generic.ResultBUser"].Value.Properties["data"].Ref != prefix+"b.User" {
+		t.Fatalf("不同包的类型参数错误：%v %v", batch["a"].Ref, batch["b"].Ref)
+	}
+	paged := schemas["example.com.generic.PagedRoleListMap"]
+	if paged == nil || paged.Value.Properties["cursor"] == nil || paged.Value.Properties["total"] == nil ||
+		paged.Value.Properties["list"].Value.Items.Value.AdditionalProperties.Schema.Value.Items.Ref != prefix+"Role" {
+		t.Fatal("嵌入的泛型应该展开")
+	}
+	for name := range schemas {
+		if name == "example.com.generic.Result" || strings.Contains(name, "{") {
+			t.Fatalf("泛型类型本身不应该生成：%v", name)
+		}
+	}
+	// 实例名称只由类型决定，去掉重名的结构体和路由后其他包的类型参数仍然加上包名
+	buf, err := os.ReadFile("./testdata/generic/route.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	buf = bytes.Replace(buf, []byte("type ResultUser struct {\n\tOther string `json:\"other\"` // 其他\n}"), nil, 1)
+	buf = buf[:bytes.Index(buf, []byte("// Collide"))]
+	opts := Options{
+		RootDir:  "./testdata/generic",
+		RouteDir: "./testdata/generic",
+		DocPath:  "./testdata/generic/doc.go",
+		Overlay:  map[string][]byte{"./testdata/generic/route.go": buf},
+	}
+	if doc, err = Generate(context.Background(), opts); err != nil {
+		t.Fatal(err)
+	}
+	batch = doc.Components.Schemas["example.com.generic.Batch"].Value.Properties
+	if batch["result"].Ref != prefix+"ResultUser" || batch["a"].Ref != prefix+"ResultAUser" || batch["b"].Ref != prefix+"ResultBUser" {
+		t.Fatalf("实例名称和解析顺序有关：%v %v %v", batch["result"].Ref, batch["a"].Ref, batch["b"].Ref)
+	}
+	// 不同的实例名称相同时报错
+	opts.Overlay["./testdata/generic/route.go"] = bytes.Replace(buf, []byte("type Batch struct {\n"),
+		[]byte("type AUser struct{}\n\ntype Batch struct {\n\tC Result[AUser] `json:\"c\"`\n"), 1)
+	var e *Error
+	if _, err = Generate(context.Background(), opts); !errors.As(err, &e) || len(e.Diagnostics) != 1 ||
+		e.Diagnostics[0].Code != CodeRepeat {
+		t.Fatalf("实例名称重复应该返回错误：%v", err)
+	}
+}
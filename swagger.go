@@ -0,0 +1,355 @@
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/getkin/kin-openapi/openapi2"
+	"github.com/getkin/kin-openapi/openapi2conv"
+	"github.com/getkin/kin-openapi/openapi3"
+	"go/token"
+	"io"
+	"io/fs"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// swagger2.0默认的请求和返回类型，转换时优先保留
+const swaggerDefaultMediaType = "application/json"
+
+// swagger2.0支持的输出格式
+var swaggerFormatMap = map[string]struct {
+	ext     string
+	marshal func(doc *openapi2.T) ([]byte, error)
+}{
+	FormatYAML: {ext: ".yaml", marshal: func(doc *openapi2.T) ([]byte, error) {
+		return yamlMarshal(doc)
+	}},
+	FormatJSON: {ext: ".json", marshal: func(doc *openapi2.T) ([]byte, error) {
+		return json.MarshalIndent(doc, "", "    ")
+	}},
+	FormatJSONCompact: {ext: ".min.json", marshal: func(doc *openapi2.T) ([]byte, error) {
+		return json.Marshal(doc)
+	}},
+}
+
+// ToSwagger 将openapi3.0文档转换为swagger2.0文档，不修改传入的文档
+// 2.0无法表达的内容(多个请求类型、oneOf、cookie参数等)会被移除，并在返回的诊断中给出警告
+func ToSwagger(t *openapi3.T) (doc *openapi2.T, diags Diagnostics, err error) {
+	if strings.HasPrefix(t.OpenAPI, "3.1") {
+		return nil, nil, newErrorMsg(ErrorKindValidate, errorSwagger31, t.OpenAPI)
+	}
+	// 转换会修改文档中的schema，因此使用副本
+	buf, err := json.Marshal(t)
+	if err != nil {
+		return nil, nil, newError(ErrorKindValidate, err)
+	}
+	t3 := &openapi3.T{}
+	if err = json.Unmarshal(buf, t3); err != nil {
+		return nil, nil, newError(ErrorKindValidate, err)
+	}
+	s := &swaggerHandle{t: t3, visited: map[*openapi3.Schema]bool{}, diags: &diagnosticHandle{}}
+	produces := s.prepare()
+	if doc, err = openapi2conv.FromV3(t3); err != nil {
+		return nil, nil, newError(ErrorKindValidate, err)
+	}
+	for path, methodMap := range produces {
+		for method, list := range methodMap {
+			if operation := doc.Paths[path].GetOperation(method); operation != nil {
+				operation.Produces = list
+			}
+		}
+	}
+	return doc, s.diags.list, nil
+}
+
+// 将文档中swagger2.0无法表达的内容移除，并记录警告
+type swaggerHandle struct {
+	t       *openapi3.T
+	visited map[*openapi3.Schema]bool
+	diags   *diagnosticHandle
+}
+
+// 返回每个路由的返回类型，key为路由和请求方式
+func (s *swaggerHandle) prepare() (produces map[string]map[string][]string) {
+	produces = map[string]map[string][]string{}
+	if len(s.t.Servers) > 1 {
+		s.warn(CodeSwaggerServer, "servers", "swagger2.0只支持一个服务地址，只保留 %v", s.t.Servers[0].URL)
+	}
+	if s.t.Components != nil {
+		for _, name := range sortedKeys(s.t.Components.Schemas) {
+			s.schema("components.schemas."+name, s.t.Components.Schemas[name])
+		}
+		for _, name := range sortedKeys(s.t.Components.Parameters) {
+			if s.isCookie("components.parameters."+name, s.t.Components.Parameters[name]) {
+				delete(s.t.Components.Parameters, name)
+			}
+		}
+		for _, name := range sortedKeys(s.t.Components.RequestBodies) {
+			s.requestBody("components.requestBodies."+name, s.t.Components.RequestBodies[name])
+		}
+		for _, name := range sortedKeys(s.t.Components.Responses) {
+			s.response("components.responses."+name, s.t.Components.Responses[name])
+		}
+	}
+	if s.t.Paths == nil {
+		return
+	}
+	pathMap := s.t.Paths.Map()
+	for _, path := range sortedKeys(pathMap) {
+		pathItem := pathMap[path]
+		pathItem.Parameters = s.parameters("paths."+path+".parameters", pathItem.Parameters)
+		operations := pathItem.Operations()
+		for _, method := range sortedKeys(operations) {
+			operation := operations[method]
+			key := "paths." + path + "." + strings.ToLower(method)
+			if operation.Servers != nil {
+				s.warn(CodeSwaggerServer, key+".servers", "swagger2.0不支持路由的服务地址，已移除")
+				operation.Servers = nil
+			}
+			if len(operation.Callbacks) > 0 {
+				s.warn(CodeSwaggerCallback, key+".callbacks", "swagger2.0不支持回调，已移除 %v",
+					strings.Join(sortedKeys(operation.Callbacks), ","))
+				operation.Callbacks = nil
+			}
+			operation.Parameters = s.parameters(key+".parameters", operation.Parameters)
+			s.requestBody(key+".requestBody", operation.RequestBody)
+			if operation.Responses == nil {
+				continue
+			}
+			var list []string
+			responseMap := operation.Responses.Map()
+			operation.Responses = &openapi3.Responses{}
+			for _, status := range sortedKeys(responseMap) {
+				if strings.HasSuffix(status, "XX") {
+					s.warn(CodeSwaggerStatus, key+".responses."+status, "swagger2.0不支持状态码范围，已移除返回 %v", status)
+					continue
+				}
+				operation.Responses.Set(status, responseMap[status])
+				mediaType := s.response(key+".responses."+status, responseMap[status])
+				if mediaType != "" && inArray(mediaType, list) == -1 {
+					list = append(list, mediaType)
+				}
+			}
+			if len(list) > 0 {
+				sort.Strings(list)
+				if produces[path] == nil {
+					produces[path] = map[string][]string{}
+				}
+				produces[path][method] = list
+			}
+		}
+	}
+	return
+}
+
+func (s *swaggerHandle) parameters(key string, list openapi3.Parameters) (rs openapi3.Parameters) {
+	for _, v := range list {
+		if s.isCookie(key, v) {
+			continue
+		}
+		// swagger2.0的参数不能是对象，例如 style=deepObject
+		if v.Value != nil && v.Value.Schema != nil {
+			if schema := s.resolve(v.Value.Schema); schema == nil || schema.Type == openapi3.TypeObject {
+				s.warn(CodeSwaggerSchema, key+"."+v.Value.Name, "swagger2.0的参数不支持对象，已移除参数 %v", v.Value.Name)
+				continue
+			}
+		}
+		if v.Value != nil {
+			s.schema(key+"."+v.Value.Name, v.Value.Schema)
+		}
+		rs = append(rs, v)
+	}
+	return
+}
+
+func (s *swaggerHandle) isCookie(key string, param *openapi3.ParameterRef) bool {
+	if param == nil || param.Value == nil || param.Value.In != openapi3.ParameterInCookie {
+		return false
+	}
+	s.warn(CodeSwaggerCookie, key, "swagger2.0不支持cookie参数，已移除参数 %v", param.Value.Name)
+	return true
+}
+
+// 请求只保留一个类型
+func (s *swaggerHandle) requestBody(key string, body *openapi3.RequestBodyRef) {
+	if body == nil || body.Value == nil {
+		return
+	}
+	body.Value.Content = s.content(key, body.Value.Content, "请求")
+}
+
+// 返回只保留一个类型，并以application/json为key，使转换时保留返回结构，返回保留的类型
+func (s *swaggerHandle) response(key string, response *openapi3.ResponseRef) (mediaType string) {
+	if response == nil || response.Value == nil {
+		return
+	}
+	for _, name := range sortedKeys(response.Value.Headers) {
+		header := response.Value.Headers[name]
+		// swagger2.0没有可引用的返回头，使用定义的返回头
+		if header.Ref != "" && s.t.Components != nil {
+			if v := s.t.Components.Headers[strings.TrimPrefix(header.Ref, "#/components/headers/")]; v != nil {
+				header = &openapi3.HeaderRef{Value: v.Value}
+				response.Value.Headers[name] = header
+			}
+		}
+		if header.Value != nil {
+			s.schema(key+".headers."+name, header.Value.Schema)
+		}
+	}
+	content := s.content(key, response.Value.Content, "返回")
+	for k, v := range content {
+		mediaType = k
+		response.Value.Content = openapi3.Content{swaggerDefaultMediaType: v}
+	}
+	return
+}
+
+func (s *swaggerHandle) content(key string, content openapi3.Content, title string) openapi3.Content {
+	if len(content) == 0 {
+		return content
+	}
+	list := sortedKeys(content)
+	keep := list[0]
+	if content[swaggerDefaultMediaType] != nil {
+		keep = swaggerDefaultMediaType
+	}
+	if len(list) > 1 {
+		var drops []string
+		for _, v := range list {
+			if v != keep {
+				drops = append(drops, v)
+			}
+		}
+		s.warn(CodeSwaggerMediaType, key, "swagger2.0的%v只支持一个类型，保留 %v，已移除 %v", title, keep,
+			strings.Join(drops, ","))
+	}
+	s.schema(key+".content."+keep, content[keep].Schema)
+	if keep == "application/x-www-form-urlencoded" || keep == "multipart/form-data" {
+		s.formData(key+".content."+keep, content[keep])
+	}
+	return openapi3.Content{keep: content[keep]}
+}
+
+// 表单内容转换为formData参数，引用的结构体需要展开，对象字段无法表达
+func (s *swaggerHandle) formData(key string, mediaType *openapi3.MediaType) {
+	schema := s.resolve(mediaType.Schema)
+	if schema == nil {
+		mediaType.Schema = &openapi3.SchemaRef{Value: &openapi3.Schema{Type: openapi3.TypeObject}}
+		return
+	}
+	value := *schema
+	value.Properties = openapi3.Schemas{}
+	for _, name := range sortedKeys(schema.Properties) {
+		property := s.resolve(schema.Properties[name])
+		if property == nil || property.Type == openapi3.TypeObject {
+			s.warn(CodeSwaggerSchema, key+".properties."+name, "swagger2.0的formData不支持对象，已移除字段 %v", name)
+			continue
+		}
+		value.Properties[name] = &openapi3.SchemaRef{Value: property}
+	}
+	mediaType.Schema = &openapi3.SchemaRef{Value: &value}
+}
+
+func (s *swaggerHandle) resolve(schema *openapi3.SchemaRef) *openapi3.Schema {
+	if schema == nil {
+		return nil
+	}
+	if schema.Ref == "" {
+		return schema.Value
+	}
+	if s.t.Components == nil {
+		return nil
+	}
+	if v := s.t.Components.Schemas[strings.TrimPrefix(schema.Ref, "#/components/schemas/")]; v != nil {
+		return v.Value
+	}
+	return nil
+}
+
+// 移除schema中的oneOf、anyOf和not
+func (s *swaggerHandle) schema(key string, schema *openapi3.SchemaRef) {
+	if schema == nil || schema.Ref != "" || schema.Value == nil || s.visited[schema.Value] {
+		return
+	}
+	value := schema.Value
+	s.visited[value] = true
+	var drops []string
+	if len(value.OneOf) > 0 {
+		drops = append(drops, "oneOf")
+	}
+	if len(value.AnyOf) > 0 {
+		drops = append(drops, "anyOf")
+	}
+	if value.Not != nil {
+		drops = append(drops, "not")
+	}
+	if len(drops) > 0 {
+		s.warn(CodeSwaggerSchema, key, "swagger2.0不支持 %v，已移除", strings.Join(drops, ","))
+		value.OneOf, value.AnyOf, value.Not = nil, nil, nil
+	}
+	for _, name := range sortedKeys(value.Properties) {
+		s.schema(key+".properties."+name, value.Properties[name])
+	}
+	for i, v := range value.AllOf {
+		s.schema(fmt.Sprintf("%v.allOf.%v", key, i), v)
+	}
+	s.schema(key+".items", value.Items)
+	s.schema(key+".additionalProperties", value.AdditionalProperties.Schema)
+}
+
+func (s *swaggerHandle) warn(code, key, format string, args ...interface{}) {
+	s.diags.add(SeverityWarning, code, key, fmt.Sprintf(format, args...), token.Position{})
+}
+
+// SwaggerEmitter 将文档转换为swagger2.0后按格式输出，Path为空时输出到Writer
+type SwaggerEmitter struct {
+	Format    string           // 输出格式，值包括 yaml,json,json-compact
+	Path      string           // 文件路径，为空时输出到Writer
+	Perm      fs.FileMode      // 文件权限，为0时使用0644
+	Writer    io.Writer        // Path为空时的输出，为nil时输出到标准输出
+	OnWarning func(Diagnostic) // 转换时无法表达内容的警告回调
+}
+
+// NewSwaggerEmitter 输出到 dir 目录中的 name+格式后缀 文件
+func NewSwaggerEmitter(format, dir, name string, perm fs.FileMode) (*SwaggerEmitter, error) {
+	info, ok := swaggerFormatMap[format]
+	if !ok {
+		return nil, newErrorMsg(ErrorKindWrite, "swagger输出格式"+errorNotIn, format, strings.Join(swaggerFormats(), ","))
+	}
+	return &SwaggerEmitter{
+		Format: format,
+		Path:   filepath.Join(dir, name+info.ext),
+		Perm:   perm,
+	}, nil
+}
+
+func (e *SwaggerEmitter) Emit(t *openapi3.T) (err error) {
+	info, ok := swaggerFormatMap[e.Format]
+	if !ok {
+		return newErrorMsg(ErrorKindWrite, "swagger输出格式"+errorNotIn, e.Format, strings.Join(swaggerFormats(), ","))
+	}
+	doc, diags, err := ToSwagger(t)
+	if err != nil {
+		return
+	}
+	if e.OnWarning != nil {
+		for _, v := range diags {
+			e.OnWarning(v)
+		}
+	}
+	var buf []byte
+	if buf, err = info.marshal(doc); err != nil {
+		return newError(ErrorKindWrite, err)
+	}
+	return writeOutput(e.Path, e.Perm, e.Writer, buf)
+}
+
+func swaggerFormats() []string {
+	var list []string
+	for k := range swaggerFormatMap {
+		list = append(list, k)
+	}
+	sort.Strings(list)
+	return list
+}
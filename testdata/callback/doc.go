@@ -0,0 +1,7 @@
+// Package callback
+// @info.title: 回调
+// @info.version: 1.0.0
+// @webhooks: name=order.paid; method=post; summary=订单已支付
+// @webhooks.body: name=order.paid; in=application/json; content=callback.OrderPaid; required
+// @webhooks.res: name=order.paid; status=204; desc=接收成功
+package callback
@@ -0,0 +1,24 @@
+package callback
+
+type Export struct {
+	CallbackUrl string `json:"callbackUrl"`
+}
+
+type OrderPaid struct {
+	OrderId int64 `json:"orderId"`
+}
+
+type ExportDone struct {
+	FileUrl string `json:"fileUrl"`
+}
+
+// Export 异步导出
+// @summary: 异步导出
+// @body: in=application/json; content=callback.Export
+// @res: status=202; desc=已接受
+// @callback: name=exportDone; expression={$request.body#/callbackUrl}; method=post; summary=导出完成
+// @callback.body: name=exportDone; in=application/json; content=callback.ExportDone
+// @callback.res: name=exportDone; status=200; desc=接收成功
+// @router: method=post;path=/exports
+func Export() {
+}
@@ -0,0 +1,11 @@
+// Package components
+// @info.title: 可引用组件
+// @info.version: 1.0.0
+// @components.examples: field=ItemExample; summary=示例; value={"id":1,"name":"item"}
+// @components.examples: field=PageExample; value=20
+// @components.headers: field=RateLimit; type=int32; desc=剩余请求次数
+// @components.parameters: field=PageSize; in=query; name=page_size; type=integer; desc=每页数量; examples=PageExample
+// @components.requestBodies: field=CreateItem; in=application/json; content=components.Item; desc=创建; required; examples=ItemExample
+// @components.responses: field=NotFound; in=application/json; content=components.Error; desc=不存在
+// @global.res: status=404; ref=NotFound
+package components
@@ -0,0 +1,29 @@
+package components
+
+type Item struct {
+	Id   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+type Error struct {
+	Message string `json:"message"`
+}
+
+// List 列表
+// @summary: 列表
+// @param: ref=PageSize
+// @res: status=200; in=application/json; content=[]components.Item; desc=成功
+// @res.header: status=200; ref=RateLimit
+// @res.header: name=X-Request-Id; desc=请求id
+// @router: method=get;path=/items
+func List() {
+}
+
+// Create 创建
+// @summary: 创建
+// @body: ref=CreateItem
+// @res: status=201; in=application/json; content=components.Item; desc=创建成功; examples=ItemExample
+// @res.header: status=404; name=X-Request-Id; desc=请求id
+// @router: method=post;path=/items
+func Create() {
+}
@@ -0,0 +1,5 @@
+// Package order
+// @info.title: order 服务
+// @info.version: 1.0.0
+// @tags: name=order
+package order
@@ -0,0 +1,7 @@
+package models
+
+// Order order 信息
+type Order struct {
+	ID   int    `json:"id"`   // 主键
+	Name string `json:"name"` // 名称
+}
@@ -0,0 +1,10 @@
+package order
+
+// GetOrder
+// @summary: 获取order
+// @tags: order
+// @param: in=path; name=id; type=integer; required
+// @res: status=200; in=application/json; content=models.Order; desc=成功
+// @router: method=get;path=/order/{id}
+func GetOrder() {
+}
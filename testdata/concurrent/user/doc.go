@@ -0,0 +1,5 @@
+// Package user
+// @info.title: user 服务
+// @info.version: 1.0.0
+// @tags: name=user
+package user
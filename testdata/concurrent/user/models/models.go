@@ -0,0 +1,7 @@
+package models
+
+// User user 信息
+type User struct {
+	ID   int    `json:"id"`   // 主键
+	Name string `json:"name"` // 名称
+}
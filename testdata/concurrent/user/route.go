@@ -0,0 +1,10 @@
+package user
+
+// GetUser
+// @summary: 获取user
+// @tags: user
+// @param: in=path; name=id; type=integer; required
+// @res: status=200; in=application/json; content=models.User; desc=成功
+// @router: method=get;path=/user/{id}
+func GetUser() {
+}
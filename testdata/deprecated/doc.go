@@ -0,0 +1,4 @@
+// Package deprecated
+// @info.title: 废弃
+// @info.version: 1.0.0
+package deprecated
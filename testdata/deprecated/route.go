@@ -0,0 +1,40 @@
+package deprecated
+
+type Owner struct {
+	Name string `json:"name"` // 名称
+}
+
+type Item struct {
+	Name string `json:"name" openapi:"deprecated"` // 名称
+	// Deprecated: 使用 name
+	Title string `json:"title"`
+	Keep  string `json:"keep" deprecated:"false"`    // Deprecated: 标签优先
+	Owner Owner  `json:"owner" openapi:"deprecated"` // 主人
+}
+
+// Old 旧接口
+// @summary: 旧接口
+// @description: 获取列表
+// @deprecated: reason=使用 /v2/items; sunset=2026-12-31
+// @param: in=query; name=page; type=integer; deprecated
+// @res: status=200; in=application/json; content=deprecated.Item; desc=成功
+// @router: method=get;path=/items
+func Old() {
+}
+
+// Legacy 旧接口
+//
+// Deprecated: 使用 Old
+// 替代。
+//
+// @summary: 旧接口
+// @router: method=get;path=/legacy
+func Legacy() {
+}
+
+// Bare 不带原因
+// @summary: 不带原因
+// @deprecated
+// @router: method=get;path=/bare
+func Bare() {
+}
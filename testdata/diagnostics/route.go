@@ -0,0 +1,14 @@
+package diagnostics
+
+// List 多个错误的注释
+// @param: in=body; name=id; type=string
+// @res: status=abc; in=application/json
+// @router: method=get;path=/list
+func List() {
+}
+
+// Info 错误的注释
+// @param: in=query; name=id; required=yes
+// @router: method=fetch;path=/info
+func Info() {
+}
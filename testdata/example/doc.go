@@ -0,0 +1,4 @@
+// Package example
+// @info.title: 示例值
+// @info.version: 1.0.0
+package example
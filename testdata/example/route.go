@@ -0,0 +1,19 @@
+package example
+
+type Status string
+
+type Item struct {
+	Phone  string `json:"phone" example:"13800000000"`    // 手机号
+	Code   string `json:"code" example:"001" default:"0"` // 编码
+	Flag   string `json:"flag" example:"true"`            // 标记
+	Price  string `json:"price" example:"1.5"`            // 价格
+	Status Status `json:"status" example:"1"`             // 状态
+	Count  int    `json:"count" default:"1"`              // 数量
+}
+
+// Get 获取
+// @summary: 获取
+// @res: status=200; in=application/json; content=example.Item; desc=成功
+// @router: method=get;path=/item
+func Get() {
+}
@@ -0,0 +1,7 @@
+// Package extension
+// @info.title: 扩展字段
+// @info.version: 1.0.0
+// @servers: url=https://api.example.com
+// @x-logo: {"url":"https://example.com/logo.png"}
+// @x-owner: platform
+package extension
@@ -0,0 +1,14 @@
+package extension
+
+// Upload 上传
+// @summary: 上传
+// @body: in=application/octet-stream; desc=文件
+// @res: status=201; desc=上传成功
+// @externalDocs.description: 上传手册
+// @externalDocs.url: https://wiki.example.com/upload
+// @servers: url=https://cdn.example.com; description=文件服务
+// @x-ratelimit: 100
+// @x-amazon-apigateway-integration: {"type": "http_proxy", "httpMethod": "POST", "uri": "https://cdn.example.com/upload"}
+// @router: method=post;path=/upload
+func Upload() {
+}
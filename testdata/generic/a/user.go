@@ -0,0 +1,5 @@
+package a
+
+type User struct {
+	Id int64 `json:"id"` // 主键
+}
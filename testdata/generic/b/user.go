@@ -0,0 +1,5 @@
+package b
+
+type User struct {
+	Email string `json:"email"` // 邮箱
+}
@@ -0,0 +1,4 @@
+// Package generic
+// @info.title: 泛型
+// @info.version: 1.0.0
+package generic
@@ -0,0 +1,84 @@
+package generic
+
+import (
+	"example.com/generic/a"
+	"example.com/generic/b"
+)
+
+// Result 通用返回
+type Result[T any] struct {
+	Code int    `json:"code"` // 状态码
+	Msg  string `json:"msg"`  // 信息
+	Data T      `json:"data"` // 数据
+}
+
+// Page 分页
+type Page[T any] struct {
+	Total int64 `json:"total"` // 总数
+	List  []T   `json:"list"`  // 列表
+}
+
+// Paged 嵌入泛型的分页
+type Paged[T any] struct {
+	Page[T]
+	Cursor string `json:"cursor"` // 游标
+}
+
+type Pair[K comparable, V any] struct {
+	Key   K `json:"key"`
+	Value V `json:"value"`
+}
+
+type List[T any] []T
+
+type User struct {
+	Name string `json:"name"` // 名称
+}
+
+type Role struct {
+	Code string `json:"code"` // 编码
+}
+
+// ResultUser 和 Result[User] 实例的名称相同
+type ResultUser struct {
+	Other string `json:"other"` // 其他
+}
+
+type Batch struct {
+	A      Result[a.User]           `json:"a"`
+	B      Result[b.User]           `json:"b"`
+	Result Result[User]             `json:"result"`
+	Roles  List[Role]               `json:"roles"`
+	Pair   *Pair[string, User]      `json:"pair"`
+	Pages  Paged[map[string][]Role] `json:"pages"`
+	Nested Result[Result[User]]     `json:"nested"`
+}
+
+// Get 用户
+// @summary: 用户
+// @res: status=200; in=application/json; desc=成功; content=generic.Result[generic.User]
+// @router: method=get;path=/user
+func Get() {
+}
+
+// List 用户列表
+// @summary: 用户列表
+// @res: status=200; in=application/json; desc=成功; content=generic.Result[generic.Page[generic.User]]
+// @router: method=get;path=/users
+func ListUsers() {
+}
+
+// Pairs 键值对
+// @summary: 键值对
+// @body: in=application/json; content=generic.Batch
+// @res: status=200; in=application/json; desc=成功; content=generic.Pair[generic.User, generic.Role]
+// @router: method=post;path=/pairs
+func Pairs() {
+}
+
+// Collide 重名
+// @summary: 重名
+// @res: status=200; in=application/json; desc=成功; content=generic.ResultUser
+// @router: method=get;path=/collide
+func Collide() {
+}
@@ -0,0 +1,4 @@
+// Package jsontag
+// @info.title: json标签
+// @info.version: 1.0.0
+package jsontag
@@ -0,0 +1,3 @@
+module example.com/jsontag
+
+go 1.18
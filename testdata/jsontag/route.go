@@ -0,0 +1,35 @@
+package jsontag
+
+type Audit struct {
+	CreatedBy string `json:"created_by"`           // 创建人
+	UpdatedBy string `json:"updated_by,omitempty"` // 更新人
+}
+
+type Meta struct {
+	Source string `json:"source"` // 来源
+}
+
+type User struct {
+	Id       int64             `json:"id,string" example:"1001"`        // 主键
+	Score    float64           `json:"score,omitempty,string"`          // 分数
+	Active   bool              `json:"active,string"`                   // 是否启用
+	Code     string            `json:"code,string"`                     // 编码
+	Name     string            `json:"name"`                            // 名称
+	Nickname string            `json:"nickname,omitempty"`              // 昵称
+	Email    *string           `json:"email"`                           // 邮箱
+	Phone    string            `json:"phone,omitempty" required:"true"` // 手机号
+	Remark   string            `json:"remark" openapi:"required=false"` // 备注
+	Age      int               `json:",omitempty"`                      // 年龄
+	Dash     string            `json:"-,"`                              // 名称为-
+	Ignore   string            `json:"-"`                               // 忽略
+	Audit    Audit             `json:",inline"`                         // 审计信息
+	Meta     *Meta             `json:",inline"`                         // 元信息
+	Extra    map[string]string `json:"extra,inline"`                    // 扩展信息
+}
+
+// Get 用户详情
+// @summary: 用户详情
+// @res: in=application/json; desc=成功; content=jsontag.User
+// @router: method=get;path=/user
+func Get() {
+}
@@ -0,0 +1,4 @@
+// Package mapschema
+// @info.title: map类型
+// @info.version: 1.0.0
+package mapschema
@@ -0,0 +1,3 @@
+module example.com/mapschema
+
+go 1.18
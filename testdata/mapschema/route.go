@@ -0,0 +1,23 @@
+package mapschema
+
+type Tag struct {
+	Name string `json:"name"` // 名称
+}
+
+type Stats struct {
+	Counts map[string]int64        `json:"counts"` // 计数
+	Tags   map[string]Tag          `json:"tags"`   // 标签
+	Scores map[int]float64         `json:"scores"` // 分数
+	Groups map[string][]Tag        `json:"groups"` // 分组
+	Extra  map[string]interface{}  `json:"extra"`  // 扩展信息
+	Meta   map[string]any          `json:"meta"`   // 元数据
+	Nested map[string]map[bool]int `json:"nested"` // 嵌套
+	Value  interface{}             `json:"value"`  // 任意值
+}
+
+// Stats 统计
+// @summary: 统计
+// @res: in=application/json; desc=成功; content=mapschema.Stats
+// @router: method=get;path=/stats
+func GetStats() {
+}
@@ -0,0 +1,4 @@
+// Package mediatype
+// @info.title: 媒体类型
+// @info.version: 1.0.0
+package mediatype
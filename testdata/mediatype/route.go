@@ -0,0 +1,22 @@
+package mediatype
+
+type Problem struct {
+	Title  string `json:"title"`
+	Status int    `json:"status"`
+}
+
+// Upload 上传
+// @summary: 上传
+// @body: in=image/*,application/octet-stream; desc=文件
+// @res: status=200; in=application/vnd.acme.v2+json; content=mediatype.Problem; desc=成功
+// @res: status=4XX; in=application/problem+json; content=mediatype.Problem; desc=错误
+// @router: method=post;path=/files
+func Upload() {
+}
+
+// Download 下载
+// @summary: 下载
+// @res: status=200; in=application/pdf,text/csv,text/event-stream,application/x-ndjson; desc=文件
+// @router: method=get;path=/files
+func Download() {
+}
@@ -0,0 +1,4 @@
+// Package app
+// @info.title: 模块解析
+// @info.version: 1.0.0
+package app
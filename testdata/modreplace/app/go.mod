@@ -0,0 +1,16 @@
+module example.com/app
+
+go 1.18
+
+require example.com/shared v0.0.0
+
+require github.com/Acme/Types v1.2.3
+
+require (
+	example.com/old v1.0.0
+	example.com/other v1.0.0 // indirect
+)
+
+replace example.com/shared => ../shared
+
+replace example.com/old v1.0.0 => example.com/new v1.1.0
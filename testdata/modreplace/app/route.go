@@ -0,0 +1,22 @@
+package app
+
+// Shared 本地replace的模块
+// @summary: 本地replace的模块
+// @res: status=200; in=application/json; content=example.com/shared/dto.Item; desc=成功
+// @router: method=get;path=/shared
+func Shared() {
+}
+
+// Cache 大写字母的模块路径
+// @summary: 大写字母的模块路径
+// @res: status=200; in=application/json; content=github.com/Acme/Types.Item; desc=成功
+// @router: method=get;path=/cache
+func Cache() {
+}
+
+// Replace 替换为其他版本的模块
+// @summary: 替换为其他版本的模块
+// @res: status=200; in=application/json; content=example.com/old.Item; desc=成功
+// @router: method=get;path=/replace
+func Replace() {
+}
@@ -0,0 +1,5 @@
+package dto
+
+type Item struct {
+	Shared string `json:"shared"` // 本地模块
+}
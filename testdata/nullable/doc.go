@@ -0,0 +1,4 @@
+// Package nullable
+// @info.title: 指针字段
+// @info.version: 1.0.0
+package nullable
@@ -0,0 +1,3 @@
+module example.com/nullable
+
+go 1.18
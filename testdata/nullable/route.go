@@ -0,0 +1,23 @@
+package nullable
+
+type Address struct {
+	City string `json:"city"` // 城市
+}
+
+type PatchUser struct {
+	Name     *string   `json:"name"`                              // 名称
+	Age      int       `json:"age"`                               // 年龄
+	Address  *Address  `json:"address"`                           // 地址
+	Tags     *[]string `json:"tags"`                              // 标签
+	Nickname *string   `json:"nickname" openapi:"nullable=false"` // 昵称
+	Remark   string    `json:"remark" nullable:"true"`            // 备注
+	Extra    *any      `json:"extra"`                             // 扩展
+}
+
+// Patch 修改用户
+// @summary: 修改用户
+// @body: in=application/json; content=nullable.PatchUser
+// @res: status=204; desc=修改成功
+// @router: method=patch;path=/user
+func Patch() {
+}
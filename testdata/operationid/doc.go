@@ -0,0 +1,4 @@
+// Package operationid
+// @info.title: operationId
+// @info.version: 1.0.0
+package operationid
@@ -0,0 +1,3 @@
+module example.com/operationid
+
+go 1.18
@@ -0,0 +1,30 @@
+package operationid
+
+type User struct{}
+
+// GetList 默认使用结构体名称+方法名称
+// @summary: 用户列表
+// @router: method=get;path=/users
+func (u *User) GetList() {
+}
+
+// List 多个路由共用同一个方法时添加路由后缀
+// @summary: 列表
+// @router: method=get;path=/items
+// @router: method=get;path=/v2/items
+func List() {
+}
+
+// Create 指定operationId
+// @summary: 创建
+// @operationId: createItem
+// @router: method=post;path=/items
+func Create() {
+}
+
+// Update 指定的operationId重复
+// @summary: 修改
+// @operationId: createItem
+// @router: method=put;path=/items
+func Update() {
+}
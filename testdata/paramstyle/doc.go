@@ -0,0 +1,4 @@
+// Package paramstyle
+// @info.title: 参数序列化
+// @info.version: 1.0.0
+package paramstyle
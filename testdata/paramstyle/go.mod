@@ -0,0 +1,3 @@
+module example.com/paramstyle
+
+go 1.18
@@ -0,0 +1,16 @@
+package paramstyle
+
+type Filter struct {
+	Name   string `json:"name"`
+	Status int    `json:"status"`
+}
+
+// Search 搜索
+// @summary: 搜索
+// @param: in=query; name=ids; type=array(int64); style=form; explode; enum=1,2,3; example=1,2
+// @param: in=query; name=tags; type=array(string); style=pipeDelimited; explode=false
+// @param: in=query; name=filter; type=object; style=deepObject; explode; content=paramstyle.Filter
+// @param: in=query; name=q; type=string; allowEmptyValue; allowReserved
+// @router: method=get;path=/search
+func Search() {
+}
@@ -0,0 +1,7 @@
+// Package resheader
+// @info.title: 返回头
+// @info.version: 1.0.0
+// @global.res: status=429; in=application/json; content=请求过多; desc=请求过多
+// @global.res.header: name=X-RateLimit-Remaining; type=int32; desc=剩余请求次数
+// @global.res.header: status=429; name=Retry-After; type=integer; required; desc=重试等待秒数
+package resheader
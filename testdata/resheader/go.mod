@@ -0,0 +1,3 @@
+module example.com/resheader
+
+go 1.18
@@ -0,0 +1,10 @@
+package resheader
+
+// Create 创建
+// @summary: 创建
+// @res: status=201; in=application/json; content=创建成功; desc=创建成功
+// @res.header: status=201; name=Location; type=string; required; desc=资源地址; example=/items/1
+// @res.header: status=201; name=ETag; desc=版本
+// @router: method=post;path=/items
+func Create() {
+}
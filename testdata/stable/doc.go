@@ -0,0 +1,6 @@
+// Package stable
+// @info.title: 稳定输出
+// @info.version: 1.0.0
+// @global.param: in=header; name=X-Trace; type=string; desc=链路
+// @global.res: status=500; in=application/json; content=string; desc=系统错误
+package stable
@@ -0,0 +1,35 @@
+package stable
+
+type Base struct {
+	ID int `json:"id"` // 主键
+}
+
+type Time struct {
+	Base
+	CreatedAt string `json:"created_at"` // 创建时间
+}
+
+type Item struct {
+	Time
+	Name string `json:"name"` // 名称
+}
+
+// List 多个路由共用同一个方法
+// @summary: 列表
+// @tags: zeta;alpha;mid;alpha
+// @param: in=query; name=page; type=integer; desc=页码
+// @param: in=query; name=size; type=integer; desc=数量
+// @res: status=200; in=application/json; content=[]stable.Item; desc=成功
+// @router: method=get;path=/items
+// @router: method=get;path=/v2/items
+func List() {
+}
+
+// Create 创建
+// @summary: 创建
+// @tags: beta;alpha
+// @body: in=application/json; content=stable.Item
+// @res: status=200; in=application/json; content=stable.Item; desc=成功
+// @router: method=post;path=/items
+func Create() {
+}
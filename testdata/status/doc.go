@@ -0,0 +1,5 @@
+// Package status
+// @info.title: 状态码
+// @info.version: 1.0.0
+// @global.res: status=5XX; in=application/json; content=status.Error; desc=服务器错误
+package status
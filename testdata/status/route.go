@@ -0,0 +1,27 @@
+package status
+
+type Error struct {
+	Message string `json:"message"`
+}
+
+// Delete 删除
+// @summary: 删除
+// @res: status=204; desc=删除成功
+// @res: status=4XX; in=application/json; content=status.Error; desc=请求错误
+// @router: method=delete;path=/items
+func Delete() {
+}
+
+// Update 更新
+// @summary: 更新
+// @res: status=default; in=application/json; content=status.Error; desc=错误
+// @router: method=put;path=/items
+func Update() {
+}
+
+// Check 检查
+// @summary: 检查
+// @res: status=404; in=application/json; content=status.Error; desc=不存在
+// @router: method=get;path=/items
+func Check() {
+}
@@ -0,0 +1,4 @@
+// Package structparam
+// @info.title: 结构体参数
+// @info.version: 1.0.0
+package structparam
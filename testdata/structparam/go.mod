@@ -0,0 +1,3 @@
+module example.com/structparam
+
+go 1.18
@@ -0,0 +1,25 @@
+package structparam
+
+type Page struct {
+	Page int `form:"page" binding:"required,min=1"` // 页码
+	Size int `form:"page_size" default:"20"`        // 每页数量
+}
+
+type ListFilter struct {
+	Page
+	Id      int64  `uri:"id"`                         // 主键
+	Keyword string `form:"keyword" maxLength:"20"`    // 关键字
+	Status  []int  `form:"status" openapi:"required"` // 状态
+	TraceId string `header:"X-Trace-Id"`              // 追踪id
+	Ignore  string `form:"-"`
+	Sort    string `json:"sort"` // 排序
+}
+
+// List 列表
+// @summary: 列表
+// @param: in=path; content=structparam.ListFilter
+// @param: in=query; content=structparam.ListFilter
+// @param: in=header; content=structparam.ListFilter
+// @router: method=get;path=/users/{id}/items
+func List() {
+}
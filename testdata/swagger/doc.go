@@ -0,0 +1,6 @@
+// Package swagger
+// @info.title: swagger2.0转换
+// @info.version: 1.0.0
+// @servers: url=https://api.example.com/v1; description=正式环境
+// @servers: url=http://test.example.com/v1; description=测试环境
+package swagger
@@ -0,0 +1,16 @@
+package swagger
+
+type User struct {
+	ID   int    `json:"id"`   // 主键
+	Name string `json:"name"` // 名称
+}
+
+// Create 创建
+// @summary: 创建
+// @param: in=cookie; name=session; type=string; desc=会话
+// @param: in=query; name=force; type=boolean; desc=强制创建
+// @body: in=application/json,application/xml; content=swagger.User; desc=用户
+// @res: status=200; in=application/json,application/xml; content=swagger.User; desc=成功
+// @router: method=post;path=/user
+func Create() {
+}
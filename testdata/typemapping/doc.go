@@ -0,0 +1,4 @@
+// Package typemapping
+// @info.title: 类型映射
+// @info.version: 1.0.0
+package typemapping
@@ -0,0 +1,3 @@
+module example.com/typemapping
+
+go 1.18
@@ -0,0 +1,39 @@
+package typemapping
+
+import (
+	"database/sql"
+	"encoding/json"
+	"math/big"
+	"net"
+	"net/url"
+	"time"
+)
+
+type Money struct {
+	Amount   int64  `json:"amount"`
+	Currency string `json:"currency"`
+}
+
+type Order struct {
+	CreatedAt time.Time       `json:"created_at" example:"2024-02-20T14:21:13Z"` // 创建时间
+	Timeout   time.Duration   `json:"timeout" default:"30"`                      // 超时时间
+	PaidAt    *time.Time      `json:"paid_at"`                                   // 支付时间
+	Avatar    []byte          `json:"avatar"`                                    // 头像
+	Raw       json.RawMessage `json:"raw"`                                       // 原始数据
+	Ip        net.IP          `json:"ip"`                                        // ip地址
+	Callback  url.URL         `json:"callback"`                                  // 回调地址
+	Remark    sql.NullString  `json:"remark"`                                    // 备注
+	Count     sql.NullInt64   `json:"count"`                                     // 数量
+	Total     *big.Int        `json:"total"`                                     // 总数
+	Price     Money           `json:"price"`                                     // 价格
+	Times     []time.Time     `json:"times"`                                     // 时间列表
+	UpdatedAt time.Time       `json:"updated_at" default:"2024-02-20 14:21:13"`  // 更新时间
+}
+
+// Create 创建订单
+// @summary: 创建订单
+// @body: in=application/json; content=typemapping.Order
+// @res: status=201; desc=创建成功
+// @router: method=post;path=/orders
+func Create() {
+}
@@ -0,0 +1,7 @@
+// Package v31
+// @info.title: 3.1版本
+// @info.summary: 3.1版本的简介
+// @info.version: 1.0.0
+// @info.license.name: Apache 2.0
+// @info.license.identifier: Apache-2.0
+package v31
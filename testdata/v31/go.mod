@@ -0,0 +1,3 @@
+module example.com/v31
+
+go 1.18
@@ -0,0 +1,17 @@
+package v31
+
+type Owner struct {
+	Name string `json:"name" example:"张三"` // 名称
+}
+
+type Pet struct {
+	ID    int   `json:"id" example:"1"` // 主键
+	Owner Owner `json:"owner"`          // 主人
+}
+
+// Get 获取
+// @summary: 获取
+// @res: status=200; in=application/json; content=v31.Pet; desc=成功
+// @router: method=get;path=/pet
+func Get() {
+}
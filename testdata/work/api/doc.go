@@ -0,0 +1,4 @@
+// Package api
+// @info.title: 工作区
+// @info.version: 1.0.0
+package api
@@ -0,0 +1,5 @@
+module example.com/api
+
+go 1.18
+
+require example.com/dto v1.0.0
@@ -0,0 +1,8 @@
+package api
+
+// GetOrder 获取订单
+// @summary: 获取订单
+// @res: status=200; in=application/json; content=dto.Order; desc=成功
+// @router: method=get;path=/order
+func GetOrder() {
+}
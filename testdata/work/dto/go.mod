@@ -0,0 +1,3 @@
+module example.com/dto
+
+go 1.18
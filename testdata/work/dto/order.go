@@ -0,0 +1,5 @@
+package dto
+
+type Order struct {
+	ID int `json:"id"` // 主键
+}
@@ -12,6 +12,8 @@ import (
 	"net/http"
 	"os"
 	"path/filepath"
+	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 )
@@ -81,6 +83,16 @@ func toUint64(v any) uint64 {
 	return rs
 }
 
+// 排序后的map键，保证遍历顺序稳定
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
 func toSliceInterface[t any](list []t) []interface{} {
 	var rs []interface{}
 	for _, v := range list {
@@ -254,3 +266,25 @@ func underlineToHumpFirstLower(value string) string {
 	}
 	return rs
 }
+
+// 返回的状态码，值包括三位数字 100-599、1XX-5XX 和 default，不允许 0200、+200 等写法
+var statusRegexp = regexp.MustCompile(`^(default|[1-5][0-9][0-9]|[1-5]XX)$`)
+
+func isStatus(value string) bool {
+	return statusRegexp.MatchString(value)
+}
+
+// RFC 6838 中的类型名称
+var mediaTypeNameRegexp = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9!#$&^_.+-]{0,126}$`)
+
+// 媒体类型，格式为 类型/子类型，子类型可以是通配符 *，例如 image/*、*/*
+func isMediaType(value string) bool {
+	typ, subtype, ok := strings.Cut(value, "/")
+	if !ok {
+		return false
+	}
+	if typ == "*" {
+		return subtype == "*"
+	}
+	return mediaTypeNameRegexp.MatchString(typ) && (subtype == "*" || mediaTypeNameRegexp.MatchString(subtype))
+}
@@ -0,0 +1,56 @@
+package openapi
+
+import (
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/invopop/yaml"
+)
+
+// TypeMapping go类型对应的schema，用于没有结构体或者序列化结果和结构体不一致的类型
+type TypeMapping struct {
+	Type        string `json:"type,omitempty"`        // schema类型，值包括 string,integer,number,boolean,array,object，为空时不限制类型
+	Format      string `json:"format,omitempty"`      // schema格式，例如 date-time、uuid
+	Nullable    bool   `json:"nullable,omitempty"`    // 是否可以为null
+	Description string `json:"description,omitempty"` // 字段没有注释时使用的描述
+}
+
+// 内置的类型映射，key为完整的类型名称，和结构体字段的类型一致
+// 只包括实现了json序列化方法的类型，例如 sql.NullString、url.URL 序列化为对象，按照结构体解析
+var builtinTypeMappings = map[string]TypeMapping{
+	"[]byte":                                {Type: openapi3.TypeString, Format: "byte"},
+	"[]uint8":                               {Type: openapi3.TypeString, Format: "byte"},
+	"time.Time":                             {Type: openapi3.TypeString, Format: "date-time"},
+	"time.Duration":                         {Type: openapi3.TypeInteger, Format: "int64"},
+	"encoding/json.RawMessage":              {},
+	"github.com/google/uuid.UUID":           {Type: openapi3.TypeString, Format: "uuid"},
+	"github.com/gofrs/uuid.UUID":            {Type: openapi3.TypeString, Format: "uuid"},
+	"github.com/satori/go.uuid.UUID":        {Type: openapi3.TypeString, Format: "uuid"},
+	"github.com/shopspring/decimal.Decimal": {Type: openapi3.TypeString, Format: "decimal"},
+	"net.IP":                                {Type: openapi3.TypeString, Description: "IPv4或IPv6地址"},
+	"math/big.Int":                          {Type: openapi3.TypeInteger},
+}
+
+// 类型映射中允许的schema类型
+var typeMappingTypes = []string{"", openapi3.TypeString, openapi3.TypeInteger, openapi3.TypeNumber,
+	openapi3.TypeBoolean, openapi3.TypeArray, openapi3.TypeObject}
+
+// 解析类型映射配置文件，支持json和yaml格式，key为完整的类型名称，例如
+//
+//	github.com/google/uuid.UUID:
+//	  type: string
+//	  format: uuid
+func parseTypeMappings(buf []byte) (mappings map[string]TypeMapping, err error) {
+	if err = yaml.Unmarshal(buf, &mappings); err != nil {
+		return nil, err
+	}
+	return
+}
+
+// 设置映射类型的schema
+func (m TypeMapping) setSchema(schema *openapi3.Schema) {
+	schema.Type = m.Type
+	schema.Format = m.Format
+	schema.Nullable = m.Nullable
+	if schema.Description == "" {
+		schema.Description = m.Description
+	}
+}
@@ -7,23 +7,31 @@ type validStruct struct {
 	valEnum       []string // 枚举验证
 	isUnique      bool     // 是否唯一
 	isSort        bool     // 是否map排序
+	isPos         bool     // 是否记录注释位置，用于生成时的诊断
 }
 
+// @param中不传值的字段，值为true
+var paramFlags = []string{"required", "deprecated", "explode", "allowEmptyValue", "allowReserved"}
+
 var (
 	validDocMap = map[string]*validStruct{
 		// info
-		"@info.title":          {valType: validTypeString},
-		"@info.description":    {valType: validTypeString},
-		"@info.termsOfService": {valType: validTypeString},
-		"@info.contact.name":   {valType: validTypeString},
-		"@info.contact.url":    {valType: validTypeString},
-		"@info.contact.email":  {valType: validTypeString},
-		"@info.license.name":   {valType: validTypeString},
-		"@info.license.url":    {valType: validTypeString},
-		"@info.version":        {valType: validTypeString},
+		"@info.title":              {valType: validTypeString},
+		"@info.summary":            {valType: validTypeString},
+		"@info.description":        {valType: validTypeString},
+		"@info.termsOfService":     {valType: validTypeString},
+		"@info.contact.name":       {valType: validTypeString},
+		"@info.contact.url":        {valType: validTypeString},
+		"@info.contact.email":      {valType: validTypeString},
+		"@info.license.name":       {valType: validTypeString},
+		"@info.license.url":        {valType: validTypeString},
+		"@info.license.identifier": {valType: validTypeString},
+		"@info.version":            {valType: validTypeString},
 		// externalDocs
 		"@externalDocs.description": {valType: validTypeString},
 		"@externalDocs.url":         {valType: validTypeString},
+		// 扩展字段，例如 @x-logo: {"url":"logo.png"}
+		extensionPrefix: {valType: validTypeValue},
 		// servers
 		"@servers":               {valType: validTypeMapArray, cutListSign: secondListCutSign, cutKeyValSign: secondKeyValueCutSign},
 		"@servers._.url":         {valType: validTypeString},
@@ -41,60 +49,218 @@ var (
 		"@components.securitySchemes._.name":         {valType: validTypeString},
 		"@components.securitySchemes._.in":           {valType: validTypeString, valEnum: []string{"query", "header", "cookie"}},
 		"@components.securitySchemes._.flows":        {valType: validTypeJson},
+		// components.parameters，和@param的字段一致
+		"@components.parameters":                   {valType: validTypeMapArray, cutListSign: secondListCutSign, cutKeyValSign: secondKeyValueCutSign, valEnum: paramFlags, isPos: true},
+		"@components.parameters._.field":           {valType: validTypeString, isUnique: true},
+		"@components.parameters._.in":              validRoutesMap["@param._.in"],
+		"@components.parameters._.name":            validRoutesMap["@param._.name"],
+		"@components.parameters._.type":            validRoutesMap["@param._.type"],
+		"@components.parameters._.required":        validRoutesMap["@param._.required"],
+		"@components.parameters._.desc":            validRoutesMap["@param._.desc"],
+		"@components.parameters._.minimum":         validRoutesMap["@param._.minimum"],
+		"@components.parameters._.maximum":         validRoutesMap["@param._.maximum"],
+		"@components.parameters._.minLength":       validRoutesMap["@param._.minLength"],
+		"@components.parameters._.maxLength":       validRoutesMap["@param._.maxLength"],
+		"@components.parameters._.example":         validRoutesMap["@param._.example"],
+		"@components.parameters._.examples":        validRoutesMap["@param._.examples"],
+		"@components.parameters._.style":           validRoutesMap["@param._.style"],
+		"@components.parameters._.explode":         validRoutesMap["@param._.explode"],
+		"@components.parameters._.allowEmptyValue": validRoutesMap["@param._.allowEmptyValue"],
+		"@components.parameters._.allowReserved":   validRoutesMap["@param._.allowReserved"],
+		"@components.parameters._.content":         validRoutesMap["@param._.content"],
+		"@components.parameters._.default":         validRoutesMap["@param._.default"],
+		"@components.parameters._.enum":            validRoutesMap["@param._.enum"],
+		"@components.parameters._.deprecated":      validRoutesMap["@param._.deprecated"],
+		// components.requestBodies，和@body的字段一致
+		"@components.requestBodies":            {valType: validTypeMapArray, cutListSign: secondListCutSign, cutKeyValSign: secondKeyValueCutSign, valEnum: []string{"required"}, isPos: true},
+		"@components.requestBodies._.field":    {valType: validTypeString, isUnique: true},
+		"@components.requestBodies._.in":       validRoutesMap["@body._.in"],
+		"@components.requestBodies._.content":  validRoutesMap["@body._.content"],
+		"@components.requestBodies._.desc":     validRoutesMap["@body._.desc"],
+		"@components.requestBodies._.required": validRoutesMap["@body._.required"],
+		"@components.requestBodies._.examples": validRoutesMap["@body._.examples"],
+		// components.responses，和@res的字段一致
+		"@components.responses":            {valType: validTypeMapArray, cutListSign: secondListCutSign, cutKeyValSign: secondKeyValueCutSign, isPos: true},
+		"@components.responses._.field":    {valType: validTypeString, isUnique: true},
+		"@components.responses._.in":       validRoutesMap["@res._.in"],
+		"@components.responses._.content":  validRoutesMap["@res._.content"],
+		"@components.responses._.desc":     validRoutesMap["@res._.desc"],
+		"@components.responses._.examples": validRoutesMap["@res._.examples"],
+		// components.headers，和@res.header的字段一致
+		"@components.headers":              {valType: validTypeMapArray, cutListSign: secondListCutSign, cutKeyValSign: secondKeyValueCutSign, valEnum: []string{"required", "deprecated"}, isPos: true},
+		"@components.headers._.field":      {valType: validTypeString, isUnique: true},
+		"@components.headers._.type":       validRoutesMap["@res.header._.type"],
+		"@components.headers._.required":   validRoutesMap["@res.header._.required"],
+		"@components.headers._.deprecated": validRoutesMap["@res.header._.deprecated"],
+		"@components.headers._.desc":       validRoutesMap["@res.header._.desc"],
+		"@components.headers._.example":    validRoutesMap["@res.header._.example"],
+		"@components.headers._.enum":       validRoutesMap["@res.header._.enum"],
+		// components.examples
+		"@components.examples":                 {valType: validTypeMapArray, cutListSign: secondListCutSign, cutKeyValSign: secondKeyValueCutSign, isPos: true},
+		"@components.examples._.field":         {valType: validTypeString, isUnique: true},
+		"@components.examples._.summary":       {valType: validTypeString},
+		"@components.examples._.desc":          {valType: validTypeString},
+		"@components.examples._.value":         {valType: validTypeJson},
+		"@components.examples._.externalValue": {valType: validTypeString},
+		// webhooks，只支持3.1版本，name为webhook的名称，其他和@callback一致
+		"@webhooks":                 validRoutesMap["@callback"],
+		"@webhooks._.name":          validRoutesMap["@callback._.name"],
+		"@webhooks._.method":        validRoutesMap["@callback._.method"],
+		"@webhooks._.summary":       validRoutesMap["@callback._.summary"],
+		"@webhooks._.desc":          validRoutesMap["@callback._.desc"],
+		"@webhooks._.operationId":   validRoutesMap["@callback._.operationId"],
+		"@webhooks.body":            validRoutesMap["@callback.body"],
+		"@webhooks.body._.name":     validRoutesMap["@callback.body._.name"],
+		"@webhooks.body._.in":       validRoutesMap["@callback.body._.in"],
+		"@webhooks.body._.content":  validRoutesMap["@callback.body._.content"],
+		"@webhooks.body._.desc":     validRoutesMap["@callback.body._.desc"],
+		"@webhooks.body._.required": validRoutesMap["@callback.body._.required"],
+		"@webhooks.body._.examples": validRoutesMap["@callback.body._.examples"],
+		"@webhooks.body._.ref":      validRoutesMap["@callback.body._.ref"],
+		"@webhooks.res":             validRoutesMap["@callback.res"],
+		"@webhooks.res._.name":      validRoutesMap["@callback.res._.name"],
+		"@webhooks.res._.status":    validRoutesMap["@callback.res._.status"],
+		"@webhooks.res._.in":        validRoutesMap["@callback.res._.in"],
+		"@webhooks.res._.content":   validRoutesMap["@callback.res._.content"],
+		"@webhooks.res._.desc":      validRoutesMap["@callback.res._.desc"],
+		"@webhooks.res._.examples":  validRoutesMap["@callback.res._.examples"],
+		"@webhooks.res._.ref":       validRoutesMap["@callback.res._.ref"],
 		// global.res
 		"@global.res":           validRoutesMap["@res"],
 		"@global.res._.status":  validRoutesMap["@res._.status"],
 		"@global.res._.in":      validRoutesMap["@res._.in"],
 		"@global.res._.content": validRoutesMap["@res._.content"],
 		"@global.res._.desc":    validRoutesMap["@res._.desc"],
+		"@global.res._.ref":     validRoutesMap["@res._.ref"],
+		// global.res.header
+		"@global.res.header":              validRoutesMap["@res.header"],
+		"@global.res.header._.status":     validRoutesMap["@res.header._.status"],
+		"@global.res.header._.name":       validRoutesMap["@res.header._.name"],
+		"@global.res.header._.type":       validRoutesMap["@res.header._.type"],
+		"@global.res.header._.required":   validRoutesMap["@res.header._.required"],
+		"@global.res.header._.deprecated": validRoutesMap["@res.header._.deprecated"],
+		"@global.res.header._.desc":       validRoutesMap["@res.header._.desc"],
+		"@global.res.header._.example":    validRoutesMap["@res.header._.example"],
+		"@global.res.header._.enum":       validRoutesMap["@res.header._.enum"],
+		"@global.res.header._.ref":        validRoutesMap["@res.header._.ref"],
 		// global.param
-		"@global.param":             validRoutesMap["@param"],
-		"@global.param._.in":        validRoutesMap["@param._.in"],
-		"@global.param._.name":      validRoutesMap["@param._.name"],
-		"@global.param._.type":      validRoutesMap["@param._.type"],
-		"@global.param._.required":  validRoutesMap["@param._.required"],
-		"@global.param._.desc":      validRoutesMap["@param._.desc"],
-		"@global.param._.minimum":   validRoutesMap["@param._.minimum"],
-		"@global.param._.maximum":   validRoutesMap["@param._.maximum"],
-		"@global.param._.minLength": validRoutesMap["@param._.minLength"],
-		"@global.param._.maxLength": validRoutesMap["@param._.maxLength"],
-		"@global.param._.example":   validRoutesMap["@param._.example"],
-		"@global.param._.default":   validRoutesMap["@param._.default"],
-		"@global.param._.enum":      validRoutesMap["@param._.enum"],
+		"@global.param":                   validRoutesMap["@param"],
+		"@global.param._.in":              validRoutesMap["@param._.in"],
+		"@global.param._.name":            validRoutesMap["@param._.name"],
+		"@global.param._.type":            validRoutesMap["@param._.type"],
+		"@global.param._.required":        validRoutesMap["@param._.required"],
+		"@global.param._.desc":            validRoutesMap["@param._.desc"],
+		"@global.param._.minimum":         validRoutesMap["@param._.minimum"],
+		"@global.param._.maximum":         validRoutesMap["@param._.maximum"],
+		"@global.param._.minLength":       validRoutesMap["@param._.minLength"],
+		"@global.param._.maxLength":       validRoutesMap["@param._.maxLength"],
+		"@global.param._.example":         validRoutesMap["@param._.example"],
+		"@global.param._.default":         validRoutesMap["@param._.default"],
+		"@global.param._.enum":            validRoutesMap["@param._.enum"],
+		"@global.param._.deprecated":      validRoutesMap["@param._.deprecated"],
+		"@global.param._.examples":        validRoutesMap["@param._.examples"],
+		"@global.param._.style":           validRoutesMap["@param._.style"],
+		"@global.param._.explode":         validRoutesMap["@param._.explode"],
+		"@global.param._.allowEmptyValue": validRoutesMap["@param._.allowEmptyValue"],
+		"@global.param._.allowReserved":   validRoutesMap["@param._.allowReserved"],
+		"@global.param._.content":         validRoutesMap["@param._.content"],
+		"@global.param._.ref":             validRoutesMap["@param._.ref"],
 	}
 
 	validRoutesMap = map[string]*validStruct{
 		"@summary":     {valType: validTypeString},
 		"@description": {valType: validTypeString},
-		"@tags":        {valType: validTypeMap, cutListSign: secondListCutSign},
+		"@operationId": {valType: validTypeString},
+		"@tags":        {valType: validTypeMap, cutListSign: secondListCutSign, isSort: true},
+		// externalDocs
+		"@externalDocs.description": {valType: validTypeString},
+		"@externalDocs.url":         {valType: validTypeString},
+		// servers
+		"@servers":               {valType: validTypeMapArray, cutListSign: secondListCutSign, cutKeyValSign: secondKeyValueCutSign},
+		"@servers._.url":         {valType: validTypeString},
+		"@servers._.description": {valType: validTypeString},
+		// 扩展字段
+		extensionPrefix: {valType: validTypeValue},
+		// deprecated
+		"@deprecated":          {valType: validTypeMap, cutListSign: secondListCutSign, cutKeyValSign: secondKeyValueCutSign},
+		"@deprecated._.reason": {valType: validTypeString},
+		"@deprecated._.sunset": {valType: validTypeString},
 		// param
-		"@param":             {valType: validTypeMapArray, cutListSign: secondListCutSign, cutKeyValSign: secondKeyValueCutSign, valEnum: []string{"required"}},
-		"@param._.in":        {valType: validTypeString, valEnum: []string{"query", "header", "path", "cookie"}},
-		"@param._.name":      {valType: validTypeString},
-		"@param._.type":      {valType: validTypeString},
-		"@param._.required":  {valType: validTypeBool},
-		"@param._.desc":      {valType: validTypeString},
-		"@param._.minimum":   {valType: validTypeInteger},
-		"@param._.maximum":   {valType: validTypeInteger},
-		"@param._.minLength": {valType: validTypeInteger},
-		"@param._.maxLength": {valType: validTypeInteger},
-		"@param._.example":   {valType: validTypeString},
-		"@param._.default":   {valType: validTypeString},
-		"@param._.enum":      {valType: validTypeArray, cutListSign: thirdListCutSign},
+		"@param":                   {valType: validTypeMapArray, cutListSign: secondListCutSign, cutKeyValSign: secondKeyValueCutSign, valEnum: paramFlags},
+		"@param._.in":              {valType: validTypeString, valEnum: []string{"query", "header", "path", "cookie"}},
+		"@param._.name":            {valType: validTypeString},
+		"@param._.type":            {valType: validTypeString},
+		"@param._.required":        {valType: validTypeBool},
+		"@param._.desc":            {valType: validTypeString},
+		"@param._.minimum":         {valType: validTypeInteger},
+		"@param._.maximum":         {valType: validTypeInteger},
+		"@param._.minLength":       {valType: validTypeInteger},
+		"@param._.maxLength":       {valType: validTypeInteger},
+		"@param._.example":         {valType: validTypeString},
+		"@param._.default":         {valType: validTypeString},
+		"@param._.enum":            {valType: validTypeArray, cutListSign: thirdListCutSign},
+		"@param._.deprecated":      {valType: validTypeBool},
+		"@param._.examples":        {valType: validTypeArray, cutListSign: thirdListCutSign},
+		"@param._.style":           {valType: validTypeString, valEnum: []string{"matrix", "label", "form", "simple", "spaceDelimited", "pipeDelimited", "deepObject"}},
+		"@param._.explode":         {valType: validTypeBool},
+		"@param._.allowEmptyValue": {valType: validTypeBool},
+		"@param._.allowReserved":   {valType: validTypeBool},
+		"@param._.content":         {valType: validTypeString},
+		"@param._.ref":             {valType: validTypeString},
 		// body
-		"@body":           {valType: validTypeMap, cutListSign: secondListCutSign, cutKeyValSign: secondKeyValueCutSign},
-		"@body._.in":      {valType: validTypeArray, cutListSign: thirdListCutSign, valEnum: []string{"application/json", "application/xml", "application/x-www-form-urlencoded", "multipart/form-data"}},
-		"@body._.content": {valType: validTypeString},
-		"@body._.desc":    {valType: validTypeString},
+		"@body":            {valType: validTypeMap, cutListSign: secondListCutSign, cutKeyValSign: secondKeyValueCutSign, valEnum: []string{"required"}},
+		"@body._.in":       {valType: validTypeMediaType, cutListSign: thirdListCutSign},
+		"@body._.content":  {valType: validTypeString},
+		"@body._.desc":     {valType: validTypeString},
+		"@body._.required": {valType: validTypeBool},
+		"@body._.examples": {valType: validTypeArray, cutListSign: thirdListCutSign},
+		"@body._.ref":      {valType: validTypeString},
 		// res
-		"@res":           {valType: validTypeMapArray, cutListSign: secondListCutSign, cutKeyValSign: secondKeyValueCutSign},
-		"@res._.status":  {valType: validTypeInteger},
-		"@res._.in":      {valType: validTypeArray, cutListSign: thirdListCutSign, valEnum: []string{"application/json", "application/xml"}},
-		"@res._.content": {valType: validTypeString},
-		"@res._.desc":    {valType: validTypeString},
+		"@res":            {valType: validTypeMapArray, cutListSign: secondListCutSign, cutKeyValSign: secondKeyValueCutSign},
+		"@res._.status":   {valType: validTypeStatus},
+		"@res._.in":       {valType: validTypeMediaType, cutListSign: thirdListCutSign},
+		"@res._.content":  {valType: validTypeString},
+		"@res._.desc":     {valType: validTypeString},
+		"@res._.examples": {valType: validTypeArray, cutListSign: thirdListCutSign},
+		"@res._.ref":      {valType: validTypeString},
+		// res.header
+		"@res.header":              {valType: validTypeMapArray, cutListSign: secondListCutSign, cutKeyValSign: secondKeyValueCutSign, valEnum: []string{"required", "deprecated"}},
+		"@res.header._.status":     {valType: validTypeStatus},
+		"@res.header._.name":       {valType: validTypeString},
+		"@res.header._.type":       {valType: validTypeString},
+		"@res.header._.required":   {valType: validTypeBool},
+		"@res.header._.deprecated": {valType: validTypeBool},
+		"@res.header._.desc":       {valType: validTypeString},
+		"@res.header._.example":    {valType: validTypeString},
+		"@res.header._.enum":       {valType: validTypeArray, cutListSign: thirdListCutSign},
+		"@res.header._.ref":        {valType: validTypeString},
 		// security
 		"@security":   {valType: validTypeMap, cutListSign: secondListCutSign, cutKeyValSign: secondKeyValueCutSign, isSort: true},
 		"@security._": {valType: validTypeArray, cutListSign: thirdListCutSign},
+		// callback，name关联回调的请求和返回，请求和返回的字段和@body、@res一致
+		"@callback":                 {valType: validTypeMapArray, cutListSign: secondListCutSign, cutKeyValSign: secondKeyValueCutSign, isPos: true},
+		"@callback._.name":          {valType: validTypeString},
+		"@callback._.expression":    {valType: validTypeString},
+		"@callback._.method":        {valType: validTypeString, valEnum: []string{"get", "put", "post", "delete", "options", "head", "patch"}},
+		"@callback._.summary":       {valType: validTypeString},
+		"@callback._.desc":          {valType: validTypeString},
+		"@callback._.operationId":   {valType: validTypeString},
+		"@callback.body":            {valType: validTypeMapArray, cutListSign: secondListCutSign, cutKeyValSign: secondKeyValueCutSign, valEnum: []string{"required"}},
+		"@callback.body._.name":     {valType: validTypeString},
+		"@callback.body._.in":       {valType: validTypeMediaType, cutListSign: thirdListCutSign},
+		"@callback.body._.content":  {valType: validTypeString},
+		"@callback.body._.desc":     {valType: validTypeString},
+		"@callback.body._.required": {valType: validTypeBool},
+		"@callback.body._.examples": {valType: validTypeArray, cutListSign: thirdListCutSign},
+		"@callback.body._.ref":      {valType: validTypeString},
+		"@callback.res":             {valType: validTypeMapArray, cutListSign: secondListCutSign, cutKeyValSign: secondKeyValueCutSign},
+		"@callback.res._.name":      {valType: validTypeString},
+		"@callback.res._.status":    {valType: validTypeStatus},
+		"@callback.res._.in":        {valType: validTypeMediaType, cutListSign: thirdListCutSign},
+		"@callback.res._.content":   {valType: validTypeString},
+		"@callback.res._.desc":      {valType: validTypeString},
+		"@callback.res._.examples":  {valType: validTypeArray, cutListSign: thirdListCutSign},
+		"@callback.res._.ref":       {valType: validTypeString},
 		// @router
 		"@router":          {valType: validTypeMapArray, cutListSign: secondListCutSign, cutKeyValSign: secondKeyValueCutSign},
 		"@router._.method": {valType: validTypeString, valEnum: []string{"get", "put", "post", "delete", "options", "head", "patch"}},
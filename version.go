@@ -1,3 +1,18 @@
 package openapi
 
 const Version = "3.0.3"
+
+// 可以输出的openapi版本
+const (
+	OpenAPIVersion30 = "3.0.3"
+	OpenAPIVersion31 = "3.1.0"
+)
+
+// 输出版本的简写
+var openapiVersionMap = map[string]string{
+	"":      OpenAPIVersion30,
+	"3.0":   OpenAPIVersion30,
+	"3.0.3": OpenAPIVersion30,
+	"3.1":   OpenAPIVersion31,
+	"3.1.0": OpenAPIVersion31,
+}